@@ -1,12 +1,20 @@
 package actor
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/dropbox/godropbox/container/set"
 )
 
+// ErrInvalidActorName is returned when spawning an actor with a name which is empty, contains '/' or starts with '$'.
+var ErrInvalidActorName = errors.New("actor: invalid actor name")
+
+// ErrDuplicateActorName is returned when spawning an actor with a name which is already used by its sibling.
+var ErrDuplicateActorName = errors.New("actor: duplicate actor name")
+
 // Actor.
 //
 // An actor has its name and belongs to exactly one actor system.
@@ -18,16 +26,43 @@ type Actor struct {
 	context  *ActorContext
 }
 
-// Send sends message to the actor asynchronously.
+// Send sends message to the actor.
+//
+// The message is queued in the actor's mailbox and processed asynchronously.
+// If the mailbox is full, what happens depends on its overflow policy (see WithMailbox).
+// It returns ErrMailboxFull only when the mailbox with FailSender policy is full.
 //
 // Please note that message should be wrapped in actor.Message.
 // example:
 //   actor.Send(Message{"hello"})
-func (actor *Actor) Send(msg Message) {
-	go func() {
-		defer logPanic(actor)
-		actor.context.mailbox <- msg
-	}()
+func (actor *Actor) Send(msg Message) error {
+	return actor.SendFrom(msg, nil)
+}
+
+// SendFrom sends message to the actor with its sender.
+//
+// The receiver can get the sender by context.Sender() and reply to it by context.Reply().
+// If the actor has already stopped, the message is published to system's DeadLetters.
+// example:
+//   actor.SendFrom(Message{"hello"}, context.Self)
+func (actor *Actor) SendFrom(msg Message, sender *Actor) error {
+	dropped, err := actor.context.mailbox.enqueue(envelope{message: msg, sender: sender})
+	for _, env := range dropped {
+		actor.System.publishDeadLetter(DeadLetter{
+			Message:   env.message,
+			Sender:    env.sender,
+			Recipient: actor,
+		})
+	}
+	if err == errMailboxClosed || err == errMailboxOverflow {
+		actor.System.publishDeadLetter(DeadLetter{
+			Message:   msg,
+			Sender:    sender,
+			Recipient: actor,
+		})
+		return nil
+	}
+	return err
 }
 
 // Terminate sends "Terminate" signal to the actor asynchronously.
@@ -56,6 +91,7 @@ func (actor *Actor) Kill() {
 // Monitor attaches another actor(mon) as its monitor asynchronously.
 //
 // Attached monitors will be notified its stop (terminate and kill) event with actor.Down message.
+// If the actor has already stopped, mon receives actor.Down immediately.
 func (actor *Actor) Monitor(mon *Actor) {
 	go func() {
 		defer logPanic(actor)
@@ -73,13 +109,20 @@ func (actor *Actor) Demonitor(mon *Actor) {
 	}()
 }
 
+// IsRunning returns true while the actor processes messages.
 func (actor *Actor) IsRunning() bool {
-	return actor.System.running.Contains(actor)
+	return actor.State() == Running
+}
+
+// State returns the lifecycle state of the actor.
+func (actor *Actor) State() ActorState {
+	return actor.System.registry.state(actor)
 }
 
 // Spawn creates and starts a child actor of the actor.
 //
 // This takes Receive(an type alias for actor's message handler) returns the pointer to started actor.
+// Options configure the child actor (e.g. WithMailbox).
 // The difference between top level actors which are created directly from actor system is that created child actor will be terminated or killed when the actor is terminated or killed.
 //
 // For example:
@@ -88,45 +131,123 @@ func (actor *Actor) IsRunning() bool {
 //  })
 //  actor.Terminates()
 //  // then child will also terminate.
-func (actor *Actor) Spawn(receive Receive) *Actor {
-	return actor.SpawnWithName(fmt.Sprint(actor.children.Len()), receive)
+func (actor *Actor) Spawn(receive Receive, options ...SpawnOption) *Actor {
+	return actor.SpawnProps(PropsFromReceive(receive), options...)
 }
 
 // SpawnWithName is the same as Spawn except that you can name it.
-func (actor *Actor) SpawnWithName(name string, receive Receive) *Actor {
-	system := actor.System
-	latch, child := system.spawnActor(actor.newChildActor(name, receive))
-	latch <- true
-	return child
+//
+// It panics if the name is invalid or already used by another child.
+// Please use TrySpawnWithName to handle it.
+func (actor *Actor) SpawnWithName(name string, receive Receive, options ...SpawnOption) *Actor {
+	return actor.SpawnProps(PropsFromReceive(receive).WithName(name), options...)
+}
+
+// TrySpawnWithName is the same as SpawnWithName except that it returns
+// ErrInvalidActorName or ErrDuplicateActorName instead of panic.
+func (actor *Actor) TrySpawnWithName(name string, receive Receive, options ...SpawnOption) (*Actor, error) {
+	if err := validateActorName(name); err != nil {
+		return nil, err
+	}
+	return actor.TrySpawnProps(PropsFromReceive(receive).WithName(name), options...)
+}
+
+// SpawnProps creates and starts a child actor from Props.
+//
+// Receiver produced by the props is recreated every time the child restarts.
+// Options are applied after the ones in the props.
+// It panics if the name is invalid or already used by another child.
+func (actor *Actor) SpawnProps(props Props, options ...SpawnOption) *Actor {
+	return mustActor(actor.TrySpawnProps(props, options...))
 }
 
+// TrySpawnProps is the same as SpawnProps except that it returns
+// ErrInvalidActorName or ErrDuplicateActorName instead of panic.
+func (actor *Actor) TrySpawnProps(props Props, options ...SpawnOption) (*Actor, error) {
+	if props.name != "" {
+		if err := validateActorName(props.name); err != nil {
+			return nil, err
+		}
+	}
+	child, err := actor.newChildActor(props.WithOptions(options...))
+	if err != nil {
+		return nil, err
+	}
+	return spawn(child), nil
+}
+
+// SpawnPropsWithName is the same as SpawnProps except that you can name it.
+func (actor *Actor) SpawnPropsWithName(name string, props Props, options ...SpawnOption) *Actor {
+	return actor.SpawnProps(props.WithName(name), options...)
+}
+
+// SpawnForwardActor creates and starts a child ForwardingActor which forwards messages to given actors.
+//
+// It panics if the name is invalid or already used by another child.
 func (actor *Actor) SpawnForwardActor(name string, actors ...*Actor) *ForwardingActor {
-	s := set.NewSet()
-	for _, actor := range actors {
-		s.Add(actor)
+	return actor.SpawnForwardActorWithOptions(name, actors)
+}
+
+// SpawnForwardActorWithOptions is the same as SpawnForwardActor except that it takes spawn options.
+func (actor *Actor) SpawnForwardActorWithOptions(name string, actors []*Actor, options ...SpawnOption) *ForwardingActor {
+	if err := validateActorName(name); err != nil {
+		panic(err)
+	}
+	return spawnForwardActor(actor.newChildActor, name, actors, options...)
+}
+
+// newChildActor creates a child actor which is not started yet.
+//
+// Without name in props, it generates a name which is not used by other children.
+func (actor *Actor) newChildActor(props Props) (*Actor, error) {
+	for {
+		name := props.name
+		if name == "" {
+			name = actor.children.nextName()
+		}
+		child := &Actor{
+			Name: name,
+			System: actor.System,
+			parent: actor,
+			children: newActorSet(set.NewSet()),
+		}
+		child.context = newActorContext(child, props)
+		// children is safe for concurrent use so that an actor can spawn
+		// its children in its message handler.
+		if actor.children.TryAdd(child) {
+			actor.System.registry.register(child)
+			return child, nil
+		}
+		if props.name != "" {
+			return nil, ErrDuplicateActorName
+		}
+		// the generated name was taken concurrently.  try next one.
 	}
-	forwardActor := &ForwardingActor{
-		addRecipientChan: make(chan addRecipient),
-		delRecipientChan: make(chan removeRecipient),
-		recipients: s,
+}
+
+// spawn starts an actor created by newChildActor or newTopLevelActor.
+func spawn(actor *Actor) *Actor {
+	startLatch := actor.context.start()
+	startLatch <- true
+	return actor
+}
+
+// mustActor panics with the error of newChildActor or Try* spawn functions.
+func mustActor(actor *Actor, err error) *Actor {
+	if err != nil {
+		panic(err)
 	}
-	forwardActor.Actor = actor.newChildActor(name, forwardActor.receive())
-	forwardActor.context.prePrecessHook = forwardActor.preProcessHook()
-	start := forwardActor.context.start()
-	start <- true
-	return forwardActor
-}
-
-func (actor *Actor) newChildActor(name string, receive Receive) *Actor {
-	child := &Actor{
-		Name: name,
-		System: actor.System,
-		parent: actor,
-		children: newActorSet(set.NewSet()),
+	return actor
+}
+
+// validateActorName rejects empty names and names which can't be a segment of actor path.
+//
+// Names starting with '$' are reserved for internal actors.
+func validateActorName(name string) error {
+	if name == "" || strings.Contains(name, "/") || strings.HasPrefix(name, "$") {
+		return ErrInvalidActorName
 	}
-	actor.context.addChildChan <- child
-	child.context = newActorContext(child, receive)
-	return child
+	return nil
 }
 
 // CanonicalName returns a full path name of the actor which indicates
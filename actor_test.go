@@ -0,0 +1,64 @@
+package actor
+
+import (
+	"testing"
+)
+
+func TestSpawnWithNameRejectsDuplicates(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	first, err := system.TrySpawnWithName("worker", nop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := system.TrySpawnWithName("worker", nop); err != ErrDuplicateActorName {
+		t.Fatalf("expected ErrDuplicateActorName, but got %v", err)
+	}
+	if _, err := first.TrySpawnWithName("worker", nop); err != nil {
+		t.Fatalf("names are unique only among siblings: %v", err)
+	}
+
+	// the name is released when the actor stopped.
+	watchDown(system, first, out)
+	first.Terminate()
+	expect(t, out, "terminated")
+	if _, err := system.TrySpawnWithName("worker", nop); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSpawnWithNameRejectsInvalidNames(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	for _, name := range []string{"", "a/b", "$internal"} {
+		if _, err := system.TrySpawnWithName(name, nop); err != ErrInvalidActorName {
+			t.Fatalf("%q: expected ErrInvalidActorName, but got %v", name, err)
+		}
+	}
+}
+
+func TestInternalActorsDontTakeUserNames(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	if _, err := system.TrySpawnWithName("deadLetters", nop); err != nil {
+		t.Fatal(err)
+	}
+	if system.DeadLetters.Name[0] != '$' {
+		t.Fatalf("dead letters must be named in the internal namespace: %q", system.DeadLetters.Name)
+	}
+}
+
+func TestGeneratedNamesAreUnique(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	system.SpawnWithName("1", nop)
+	names := map[string]bool{"1": true}
+	for i := 0; i < 10; i++ {
+		actor := system.Spawn(nop)
+		if names[actor.Name] {
+			t.Fatalf("%q was generated twice", actor.Name)
+		}
+		names[actor.Name] = true
+	}
+}
@@ -6,20 +6,27 @@ import (
 	"time"
 
 	"strings"
+	"sync/atomic"
 
 	"github.com/dropbox/godropbox/container/set"
 )
 
 // ActorSystem is an umbrella which maintains actor hierarchy.
 type ActorSystem struct {
-	//TODO top level supervisor
+	// accessed atomically.  this is the first field for 64-bit alignment.
+	temporaryActorSeq uint64
 	Name              string
 	wg                sync.WaitGroup
 	guardian          *Actor
 	topLevelActors    *actorSet
-	monitorForwarders set.Set
-	running           set.Set
-	stopped           set.Set
+	monitorForwarders *actorSet
+	registry          *registry
+	eventStream       *EventStream
+
+	// DeadLetters receives messages which could not be delivered as DeadLetter.
+	// Add subscribers to log or alert on them:
+	//   system.DeadLetters.Add(logger)
+	DeadLetters *ForwardingActor
 }
 
 // NewActorSystem creates an ActorSystem instance.
@@ -27,11 +34,13 @@ func NewActorSystem(name string) *ActorSystem {
 	actorSystem :=  &ActorSystem{
 		Name:              name,
 		topLevelActors:    newActorSet(set.NewSet()),
-		monitorForwarders: set.NewSet(),
-		running:           set.NewSet(),
-		stopped:           set.NewSet(),
+		monitorForwarders: newActorSet(set.NewSet()),
+		registry:          newRegistry(),
+		eventStream:       newEventStream(),
 	}
 	actorSystem.guardian = newGuardian(actorSystem)
+	// dead letters is an internal actor so that it doesn't take a name of user's actors.
+	actorSystem.DeadLetters = spawnForwardActor(actorSystem.guardian.newChildActor, actorSystem.temporaryName("deadLetters"), nil)
 	return actorSystem
 }
 
@@ -43,9 +52,8 @@ func newGuardian(system *ActorSystem) *Actor{
 		parent: nil,
 		children: newActorSet(set.NewSet()),
 	}
-	actor.context = newActorContext(actor, func(msg Message, context *ActorContext){
-		//nop
-	})
+	actor.context = newActorContext(actor, PropsFromReceive(func(msg Message, context *ActorContext){}))
+	system.registry.register(actor)
 	latch := actor.context.start()
 	latch <- true
 	return actor
@@ -54,38 +62,95 @@ func newGuardian(system *ActorSystem) *Actor{
 // Spawn creates and starts an actor in the actor system.
 //
 // This takes Receive(type ailis for actor's message handler) returns the pointer to started actor.
+// Options configure the actor (e.g. WithMailbox).
 //
 // For example, simple echo actor would be:
 //  actorSystem.Spawn(func(msg Message, context *ActorContext){
 //     fmt.Println(msg)
 //  })
-func (system *ActorSystem) Spawn(receive Receive) *Actor {
-	name := fmt.Sprint(system.topLevelActors.Len())
-	return system.SpawnWithName(name, receive)
+func (system *ActorSystem) Spawn(receive Receive, options ...SpawnOption) *Actor {
+	return system.SpawnProps(PropsFromReceive(receive), options...)
 }
 
 // SpawnWithName is the same as Spawn except that you can name it.
-func (system *ActorSystem) SpawnWithName(name string, receive Receive) *Actor {
-	startLatch, actor := system.spawnActor(system.newTopLevelActor(name, receive))
-	startLatch <- true
-	return actor
+//
+// It panics if the name is invalid or already used by another top level actor.
+// Please use TrySpawnWithName to handle it.
+func (system *ActorSystem) SpawnWithName(name string, receive Receive, options ...SpawnOption) *Actor {
+	return system.SpawnProps(PropsFromReceive(receive).WithName(name), options...)
 }
 
-func (system *ActorSystem) SpawnForwardActor(name string, actors ...*Actor) *ForwardingActor {
-	s := set.NewSet()
-	for _, actor := range actors {
-		s.Add(actor)
+// TrySpawnWithName is the same as SpawnWithName except that it returns
+// ErrInvalidActorName or ErrDuplicateActorName instead of panic.
+func (system *ActorSystem) TrySpawnWithName(name string, receive Receive, options ...SpawnOption) (*Actor, error) {
+	if err := validateActorName(name); err != nil {
+		return nil, err
+	}
+	return system.TrySpawnProps(PropsFromReceive(receive).WithName(name), options...)
+}
+
+// SpawnProps creates and starts an actor from Props in the actor system.
+//
+// Receiver produced by the props is recreated every time the actor restarts.
+// Options are applied after the ones in the props.
+// It panics if the name is invalid or already used by another top level actor.
+// For example,
+//   system.SpawnProps(actor.NewProps(func() actor.Receiver { return &counter{} }))
+func (system *ActorSystem) SpawnProps(props Props, options ...SpawnOption) *Actor {
+	return mustActor(system.TrySpawnProps(props, options...))
+}
+
+// TrySpawnProps is the same as SpawnProps except that it returns
+// ErrInvalidActorName or ErrDuplicateActorName instead of panic.
+func (system *ActorSystem) TrySpawnProps(props Props, options ...SpawnOption) (*Actor, error) {
+	if props.name != "" {
+		if err := validateActorName(props.name); err != nil {
+			return nil, err
+		}
 	}
-	forwardActor := &ForwardingActor{
-		addRecipientChan: make(chan addRecipient),
-		delRecipientChan: make(chan removeRecipient),
-		recipients: s,
+	actor, err := system.newTopLevelActor(props.WithOptions(options...))
+	if err != nil {
+		return nil, err
 	}
-	forwardActor.Actor = system.newTopLevelActor(name, forwardActor.receive())
-	forwardActor.context.prePrecessHook = forwardActor.preProcessHook()
-	start := forwardActor.context.start()
-	start <- true
-	return forwardActor
+	return spawn(actor), nil
+}
+
+// SpawnPropsWithName is the same as SpawnProps except that you can name it.
+func (system *ActorSystem) SpawnPropsWithName(name string, props Props, options ...SpawnOption) *Actor {
+	return system.SpawnProps(props.WithName(name), options...)
+}
+
+// SpawnForwardActor creates and starts a top level ForwardingActor which forwards messages to given actors.
+//
+// It panics if the name is invalid or already used by another top level actor.
+func (system *ActorSystem) SpawnForwardActor(name string, actors ...*Actor) *ForwardingActor {
+	return system.SpawnForwardActorWithOptions(name, actors)
+}
+
+// SpawnForwardActorWithOptions is the same as SpawnForwardActor except that it takes spawn options.
+//
+// For example,
+//   system.SpawnForwardActorWithOptions("fanout", []*Actor{a, b}, actor.WithMailbox(1000, actor.BlockSender))
+func (system *ActorSystem) SpawnForwardActorWithOptions(name string, actors []*Actor, options ...SpawnOption) *ForwardingActor {
+	if err := validateActorName(name); err != nil {
+		panic(err)
+	}
+	return spawnForwardActor(system.newTopLevelActor, name, actors, options...)
+}
+
+// SetSupervisorStrategy sets the strategy applied when top level actors fail asynchronously.
+//
+// Escalate directive is treated as Stop because top level actors have no supervisor to escalate to.
+// The strategy is applied to failures occurring after this returns.
+func (system *ActorSystem) SetSupervisorStrategy(strategy *SupervisorStrategy) {
+	system.guardian.context.SetSupervisorStrategy(strategy)
+}
+
+// TopLevelActors returns a snapshot of top level actors sorted by name.
+//
+// Stopped actors are not included.
+func (system *ActorSystem) TopLevelActors() []*Actor {
+	return system.topLevelActors.sorted()
 }
 
 // WaitForAllActorsStopped waits for all the actors in the actor system stopped(terminated or killed).
@@ -106,10 +171,7 @@ func (system *ActorSystem) Shutdown() {
 // It sends kill signal(Kill() method) to all the actors in the actor system after waiting for a given duration.
 func (system *ActorSystem) ShutdownIn(duration time.Duration){
 	<-time.After(duration)
-	system.topLevelActors.Subtract(system.stopped)
-	system.topLevelActors.Do(func (actor *Actor) {
-			actor.context.kill()
-	})
+	system.signalTopLevelActors((*ActorContext).kill)
 	system.WaitForAllActorsStopped()
 }
 
@@ -125,41 +187,68 @@ func (system *ActorSystem) GracefulShutdown() {
 // It sends terminate signal(Terminate() method) to all the actors in the actor system after waiting for a given duration.
 func (system *ActorSystem) GracefulShutdownIn(duration time.Duration) {
 	<-time.After(duration)
-	system.topLevelActors.Subtract(system.stopped)
-	system.topLevelActors.Do(func (actor *Actor) {
-		actor.context.terminate()
-	})
+	system.signalTopLevelActors((*ActorContext).terminate)
 	system.WaitForAllActorsStopped()
 }
 
+// signalTopLevelActors sends a stop signal to top level actors which are not stopping yet.
+//
+// Actors already Stopping are skipped: they stop anyway, and kill would block until
+// they finished stopping (e.g. waiting for their children) and delay the others.
+func (system *ActorSystem) signalTopLevelActors(signal func(context *ActorContext)) {
+	system.topLevelActors.Do(func(actor *Actor) {
+		if state := actor.State(); state == Stopping || state == Stopped {
+			return
+		}
+		signal(actor.context)
+	})
+}
+
 func (system *ActorSystem) internalShutdown(){
 	system.terminateMonitorForwarder()
 	system.guardian.Terminate()
 }
 
 func (system *ActorSystem) terminateMonitorForwarder() {
-	system.monitorForwarders.Do(func(r interface{}) {
-		if actor, ok := r.(*ForwardingActor); ok {
-			actor.context.terminate()
-		}
+	system.monitorForwarders.Do(func(actor *Actor) {
+		actor.context.terminate()
 	})
 }
 func (system *ActorSystem) spawnMonitorForwarderFor(actor *Actor) *ForwardingActor {
-	name := strings.Replace(actor.CanonicalName(), system.Name+":/", "", 1)
-	name = strings.Replace(name, "/", "-", 1)
-	forwarder := system.SpawnForwardActor(name+"_MonitorForwarder")
-	system.topLevelActors.Remove(forwarder.Actor)
-	system.monitorForwarders.Add(forwarder)
+	name := strings.Replace(strings.TrimPrefix(actor.ActorPath(), "/"), "/", "-", -1)
+	forwarder := newForwardingActor(Broadcast, nil)
+	// monitor forwarders don't watch monitors.  otherwise watching would create monitor forwarders endlessly.
+	forwarder.watchRecipients = false
+	forwarder.start(system.guardian.newChildActor, PropsFromReceive(forwarder.receive()).WithName(system.temporaryName(name+"_MonitorForwarder")))
+	system.monitorForwarders.Add(forwarder.Actor)
 	return forwarder
 }
 
-func (system *ActorSystem) newTopLevelActor(name string, receive Receive) *Actor {
-	actor := system.guardian.newChildActor(name, receive)
-	system.topLevelActors.Add(actor)
-	return actor
+func (system *ActorSystem) publishDeadLetter(deadLetter DeadLetter) {
+	if deadLetter.Recipient == system.DeadLetters.Actor {
+		// nobody can receive it.
+		return
+	}
+	system.eventStream.publish(deadLetter, deadLetter.Recipient)
+	system.DeadLetters.Send(Message{deadLetter})
 }
 
-func (system *ActorSystem) spawnActor(actor *Actor) (chan bool, *Actor) {
-	startLatch := actor.context.start()
-	return startLatch, actor
+// spawnTemporaryActor spawns an actor which is not a top level actor (e.g. reply actor of Ask).
+func (system *ActorSystem) spawnTemporaryActor(receive Receive) *Actor {
+	return spawn(mustActor(system.guardian.newChildActor(PropsFromReceive(receive).WithName(system.temporaryName("temp")))))
+}
+
+// temporaryName generates a unique name for internal actors.  it starts with '$'
+// so that it never collides with names of user's actors.
+func (system *ActorSystem) temporaryName(prefix string) string {
+	return fmt.Sprintf("$%s%d", prefix, atomic.AddUint64(&system.temporaryActorSeq, 1))
+}
+
+func (system *ActorSystem) newTopLevelActor(props Props) (*Actor, error) {
+	actor, err := system.guardian.newChildActor(props)
+	if err != nil {
+		return nil, err
+	}
+	system.topLevelActors.Add(actor)
+	return actor, nil
 }
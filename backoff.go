@@ -0,0 +1,170 @@
+package actor
+
+import (
+	"math/rand"
+	"time"
+)
+
+// BackoffOptions configures a backoff supervisor.
+//
+// The child is restarted after MinBackoff, 2*MinBackoff, 4*MinBackoff, ...
+// up to MaxBackoff.  Each delay is extended by a random amount up to
+// RandomFactor times the delay so that many children don't restart at once.
+// When the child has been running for ResetBackoff, the delay is reset to MinBackoff.
+type BackoffOptions struct {
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+	RandomFactor float64
+	ResetBackoff time.Duration
+}
+
+// BackoffChildStarted is sent to monitors of a backoff supervisor when its child was (re)started.
+type BackoffChildStarted struct {
+	Child   *Actor
+	Attempt int
+}
+
+// BackoffChildFailed is sent to monitors of a backoff supervisor when its child failed.
+// The child will be restarted when Delay elapsed after it stopped.
+type BackoffChildFailed struct {
+	Child  *Actor
+	Reason interface{}
+	Delay  time.Duration
+}
+
+// internal message used in backoff supervisor
+type backoffRestart struct{}
+
+type backoffSupervisor struct {
+	childName string
+	receive   Receive
+	options   BackoffOptions
+	child     *Actor
+	// failed child holds its name until its Down arrives.
+	failed    *Actor
+	delay     time.Duration
+	attempt   int
+	startedAt time.Time
+}
+
+// SpawnBackoffSupervisor creates and starts a top level backoff supervisor.
+//
+// The backoff supervisor spawns a child named childName with a given Receive
+// and forwards all the messages to it.  When the child panics, the child is
+// stopped and restarted after exponentially growing delays.  Messages arrived
+// while the child is waiting for restart are published to DeadLetters.
+// When the child terminated, the backoff supervisor also terminates.
+// It panics if name or childName is invalid, or name is already used.
+// For example,
+//   supervisor := system.SpawnBackoffSupervisor("db-supervisor", "db", dbReceive, actor.BackoffOptions{
+//     MinBackoff:   100 * time.Millisecond,
+//     MaxBackoff:   10 * time.Second,
+//     RandomFactor: 0.2,
+//     ResetBackoff: time.Minute,
+//   })
+//   supervisor.Send(Message{"query"}) // ==> forwarded to "db"
+func (system *ActorSystem) SpawnBackoffSupervisor(name, childName string, receive Receive, options BackoffOptions) *Actor {
+	supervisor := newBackoffSupervisor(name, childName, receive, options)
+	return supervisor.start(mustActor(system.newTopLevelActor(PropsFromReceive(supervisor.receiveMessage()).WithName(name))))
+}
+
+// SpawnBackoffSupervisor creates and starts a backoff supervisor as a child of the actor.
+//
+// Please see ActorSystem.SpawnBackoffSupervisor for details.
+func (actor *Actor) SpawnBackoffSupervisor(name, childName string, receive Receive, options BackoffOptions) *Actor {
+	supervisor := newBackoffSupervisor(name, childName, receive, options)
+	return supervisor.start(mustActor(actor.newChildActor(PropsFromReceive(supervisor.receiveMessage()).WithName(name))))
+}
+
+func newBackoffSupervisor(name, childName string, receive Receive, options BackoffOptions) *backoffSupervisor {
+	for _, n := range []string{name, childName} {
+		if err := validateActorName(n); err != nil {
+			panic(err)
+		}
+	}
+	return &backoffSupervisor{childName: childName, receive: receive, options: options}
+}
+
+func (supervisor *backoffSupervisor) start(actor *Actor) *Actor {
+	actor.context.supervisorStrategy = NewOneForOneStrategy(supervisor.decide(actor.context))
+	spawn(actor)
+	actor.Send(Message{backoffRestart{}})
+	return actor
+}
+
+// decide is called in supervisor's goroutine when the child failed.
+func (supervisor *backoffSupervisor) decide(context *ActorContext) Decider {
+	return func(reason interface{}) Directive {
+		if supervisor.options.ResetBackoff > 0 && time.Since(supervisor.startedAt) >= supervisor.options.ResetBackoff {
+			supervisor.attempt = 0
+		}
+		delay := supervisor.nextDelay()
+		supervisor.attempt++
+		context.notifyMonitors(Message{BackoffChildFailed{
+			Child:  supervisor.child,
+			Reason: reason,
+			Delay:  delay,
+		}})
+		supervisor.failed = supervisor.child
+		supervisor.child = nil
+		supervisor.delay = delay
+		return Stop
+	}
+}
+
+func (supervisor *backoffSupervisor) nextDelay() time.Duration {
+	delay := supervisor.options.MinBackoff
+	for i := 0; i < supervisor.attempt && delay < supervisor.options.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > supervisor.options.MaxBackoff {
+		delay = supervisor.options.MaxBackoff
+	}
+	if supervisor.options.RandomFactor > 0 {
+		delay += time.Duration(float64(delay) * supervisor.options.RandomFactor * rand.Float64())
+	}
+	return delay
+}
+
+func (supervisor *backoffSupervisor) receiveMessage() Receive {
+	return func(msg Message, context *ActorContext) {
+		if len(msg) == 1 {
+			switch m := msg[0].(type) {
+			case backoffRestart:
+				supervisor.child = context.Self.SpawnWithName(supervisor.childName, supervisor.receive)
+				supervisor.startedAt = time.Now()
+				// watch synchronously so that the restart never misses the child's Down.
+				supervisor.child.context.attachMonitor(context.Self)
+				context.notifyMonitors(Message{BackoffChildStarted{
+					Child:   supervisor.child,
+					Attempt: supervisor.attempt,
+				}})
+				return
+			case Down:
+				switch m.Actor {
+				case supervisor.failed:
+					// the failed child released its name.  restart it after the delay.
+					supervisor.failed = nil
+					self := context.Self
+					time.AfterFunc(supervisor.delay, func() {
+						self.Send(Message{backoffRestart{}})
+					})
+				case supervisor.child:
+					// the child stopped by itself.
+					context.Self.Terminate()
+				}
+				return
+			}
+		}
+		if supervisor.child != nil {
+			supervisor.child.SendFrom(msg, context.Sender())
+		} else {
+			// the child is waiting for restart.
+			context.Self.System.publishDeadLetter(DeadLetter{
+				Message:   msg,
+				Sender:    context.Sender(),
+				Recipient: context.Self,
+			})
+		}
+	}
+}
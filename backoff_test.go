@@ -0,0 +1,75 @@
+package actor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffSupervisorRestartsChild(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	events := make(chan interface{}, 10)
+	supervisor := system.SpawnBackoffSupervisor("supervisor", "child", func(msg Message, context *ActorContext) {
+		if msg[0] == "boom" {
+			panic("boom")
+		}
+		out <- msg[0]
+	}, BackoffOptions{MinBackoff: 20 * time.Millisecond, MaxBackoff: time.Second})
+	mon := system.Spawn(func(msg Message, context *ActorContext) {
+		switch m := msg[0].(type) {
+		case BackoffChildFailed:
+			events <- m.Delay
+		case BackoffChildStarted:
+			if m.Attempt > 0 {
+				events <- m.Attempt
+			}
+		}
+	})
+	supervisor.context.attachMonitor(mon)
+
+	supervisor.Send(Message{"hello"})
+	expect(t, out, "hello")
+	first := system.ActorOf("/supervisor/child")
+
+	supervisor.Send(Message{"boom"})
+	expect(t, events, 20*time.Millisecond)
+	expect(t, events, 1)
+	supervisor.Send(Message{"boom"})
+	expect(t, events, 40*time.Millisecond)
+	expect(t, events, 2)
+
+	supervisor.Send(Message{"hello again"})
+	expect(t, out, "hello again")
+	if child := system.ActorOf("/supervisor/child"); child == nil || child == first {
+		t.Fatal("the child must be restarted as a new actor with the same name")
+	}
+}
+
+func TestBackoffSupervisorStopsWithChild(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	supervisor := system.SpawnBackoffSupervisor("supervisor", "child", func(msg Message, context *ActorContext) {
+		out <- msg[0]
+	}, BackoffOptions{MinBackoff: time.Millisecond, MaxBackoff: time.Second})
+	watchDown(system, supervisor, out)
+
+	supervisor.Send(Message{"hello"})
+	expect(t, out, "hello")
+	system.ActorOf("/supervisor/child").Terminate()
+	expect(t, out, "terminated")
+}
+
+func TestBackoffDelay(t *testing.T) {
+	supervisor := newBackoffSupervisor("supervisor", "child", nop, BackoffOptions{
+		MinBackoff: 100 * time.Millisecond,
+		MaxBackoff: time.Second,
+	})
+	for _, want := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		if delay := supervisor.nextDelay(); delay != want*time.Millisecond {
+			t.Fatalf("attempt %d: expected %v, but got %v", supervisor.attempt, want*time.Millisecond, delay)
+		}
+		supervisor.attempt++
+	}
+}
@@ -0,0 +1,46 @@
+package actor
+
+import (
+	"testing"
+	"time"
+)
+
+// BenchmarkPingPong measures a round trip of a message between two actors.
+func BenchmarkPingPong(b *testing.B) {
+	system := NewActorSystem("bench")
+	defer system.GracefulShutdown()
+	done := make(chan struct{})
+	pong := system.Spawn(func(msg Message, context *ActorContext) {
+		context.Reply(msg)
+	})
+	ping := system.Spawn(func(msg Message, context *ActorContext) {
+		n := msg[0].(int)
+		if n == b.N {
+			done <- struct{}{}
+			return
+		}
+		context.Tell(pong, Message{n + 1})
+	})
+	b.ResetTimer()
+	ping.Send(Message{0})
+	<-done
+	b.StopTimer()
+}
+
+// BenchmarkIdleActors measures CPU time which 1000 idle actors consume in 10ms.
+//
+// Idle actors should block without consuming CPU, so cpu-ns/op should stay near zero.
+func BenchmarkIdleActors(b *testing.B) {
+	system := NewActorSystem("bench")
+	defer system.Shutdown()
+	for i := 0; i < 1000; i++ {
+		system.Spawn(nop)
+	}
+	b.ResetTimer()
+	start := cpuTime()
+	for i := 0; i < b.N; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(cpuTime()-start)/float64(b.N), "cpu-ns/op")
+}
@@ -1,7 +1,9 @@
 package actor
 
 import (
-	"runtime"
+	"fmt"
+	"os"
+	"sync"
 	"time"
 )
 
@@ -13,22 +15,44 @@ import (
 type ActorContext struct {
 	Self             *Actor
 	monitor          *ForwardingActor
+	props            Props
+	options          *spawnOptions
 	originalBehavior Receive
 	currentBehavior  Receive
 	behaviorStack    []Receive
-	mailbox          chan Message
+	receive          Receive
+	current          envelope
+	stash            []envelope
+	stashCapacity    int
+	receiveTimeout   time.Duration
+	receiveTimeoutTimer *time.Timer
+	hooks            LifecycleHooks
+	mailbox          mailbox
 	killChan         chan kill
 	attachMonChan    chan *Actor
 	detachMonChan    chan *Actor
-	addChildChan     chan *Actor
-	receiveTimeout   time.Duration
-	prePrecessHook   func()
+	failureChan      chan failure
+	directiveChan    chan supervisorDirective
+	stopCause        string
+	// done is closed when the actor stopped so that senders of control messages don't block.
+	done             chan struct{}
+	forwarder        *ForwardingActor
+
+	// strategyLock guards supervisorStrategy which the system sets from outside of guardian.
+	strategyLock       sync.RWMutex
+	supervisorStrategy *SupervisorStrategy
+	suspended          bool
+	escalated          []*Actor
+	failedMessage      Message
+	restartStats       map[*Actor]*restartStats
 
 }
 
 // internal Messages accepted by actorContext
 type terminate struct{}
-type kill struct{}
+type kill struct {
+	cause string
+}
 type shutdown struct{}
 
 // Become change actor's behavior.
@@ -90,138 +114,344 @@ func (context *ActorContext) Unwatch(actor* Actor){
 	actor.Demonitor(context.Self)
 }
 
+// Sender returns the sender of the message being processed.
+//
+// It returns nil if the message was sent by Send (not by SendFrom or Tell).
+func (context *ActorContext) Sender() *Actor {
+	return context.current.sender
+}
+
+// Reply sends a message to the sender of the message being processed.
+//
+// This is equivalent with
+//   context.Sender().SendFrom(msg, context.Self)
+// If the sender is unknown, the message is discarded.
+func (context *ActorContext) Reply(msg Message) error {
+	if sender := context.Sender(); sender != nil {
+		return sender.SendFrom(msg, context.Self)
+	}
+	return nil
+}
+
+// Tell sends a message to a given actor with myself as its sender.
+//
+// This is equivalent with
+//   actor.SendFrom(msg, context.Self)
+func (context *ActorContext) Tell(actor *Actor, msg Message) error {
+	return actor.SendFrom(msg, context.Self)
+}
+
+// SetSupervisorStrategy sets the strategy applied when children of the actor fail.
+//
+// This should be called in message handler.  If no strategy was set,
+// DefaultSupervisorStrategy is used.
+// For example,
+//   supervisor := system.Spawn(func(msg Message, context *ActorContext){
+//     context.SetSupervisorStrategy(actor.NewAllForOneStrategy(func(reason interface{}) Directive {
+//       return actor.Restart
+//     }))
+//     ...
+//   })
+func (context *ActorContext) SetSupervisorStrategy(strategy *SupervisorStrategy) {
+	context.strategyLock.Lock()
+	defer context.strategyLock.Unlock()
+	context.supervisorStrategy = strategy
+}
+
+// strategy returns the supervisor strategy currently set.
+func (context *ActorContext) strategy() *SupervisorStrategy {
+	context.strategyLock.RLock()
+	defer context.strategyLock.RUnlock()
+	return context.supervisorStrategy
+}
+
+// Children returns a snapshot of the actor's children sorted by name.
+//
+// Stopped children are not included.  Children spawned or stopped after the call
+// are not reflected in the snapshot.
+func (context *ActorContext) Children() []*Actor {
+	return context.Self.children.sorted()
+}
+
+// Child returns the actor's child with a given name, or nil if there is no such child.
+func (context *ActorContext) Child(name string) *Actor {
+	return context.Self.children.Get(name)
+}
+
+// Parent returns the actor's parent, or nil if the actor is a top level actor.
+func (context *ActorContext) Parent() *Actor {
+	if context.Self.isTopLevel() {
+		return nil
+	}
+	return context.Self.parent
+}
+
 // constructor
-func newActorContext(self *Actor, receive Receive) *ActorContext {
-	// TODO make parameters configurable
+func newActorContext(self *Actor, props Props) *ActorContext {
+	options := newSpawnOptions(props.options)
 	context := &ActorContext{
 		Self:             self,
-		originalBehavior: receive,
-		currentBehavior:  receive,
-		behaviorStack:    []Receive{receive},
-		mailbox:          make(chan Message, 100),
+		props:            props,
+		options:          options,
+		mailbox:          options.newMailbox(),
+		stashCapacity:    options.stashCapacity,
+		receive:          chainMiddleware(options.middleware),
+		supervisorStrategy: options.supervisorStrategy,
 		// buffer size for control message is 1 (cotrol method would block)
 		attachMonChan:  make(chan *Actor),
 		detachMonChan:  make(chan *Actor),
 		killChan:       make(chan kill),
-		addChildChan:   make(chan *Actor),
-		receiveTimeout: time.Duration(10) * time.Millisecond,
+		failureChan:    make(chan failure),
+		directiveChan:  make(chan supervisorDirective),
+		done:           make(chan struct{}),
 	}
+	context.incarnate()
 	return context
 }
 
-func (context *ActorContext) closeAllChan() {
-	// TODO flush remained message to deadletter channel??
+// incarnate produces a fresh receiver and resets behaviors and hooks to it.
+func (context *ActorContext) incarnate() {
+	receiver, receive := context.props.newReceiver()
+	context.originalBehavior = receive
+	context.currentBehavior = receive
+	context.behaviorStack = []Receive{receive}
+	context.hooks = lifecycleHooksOf(receiver, context.options.hooks)
+	context.receiveTimeout = context.options.receiveTimeout
+}
+
+// closeMailbox makes further messages dead letters.
+//
+// Control channels are not closed because senders may race with the stop.
+// They select done instead.
+func (context *ActorContext) closeMailbox() {
+	stashed := context.stash
+	context.stash = nil
 	go func() {
 		defer logPanic(context.Self)
-		close(context.mailbox)
-		close(context.addChildChan)
-		close(context.attachMonChan)
-		close(context.detachMonChan)
-		close(context.killChan)
+		remained := append(stashed, context.mailbox.close()...)
+		// flush remained messages to dead letters.
+		for _, env := range remained {
+			if isPoisonPill(env.message) {
+				continue
+			}
+			context.Self.System.publishDeadLetter(DeadLetter{
+				Message:   env.message,
+				Sender:    env.sender,
+				Recipient: context.Self,
+			})
+		}
 	}()
 }
 
 func (context *ActorContext) start() chan bool {
 	startLatch := make(chan bool)
+	registry := context.Self.System.registry
 	context.Self.System.wg.Add(1)
-	go func() {
+	context.options.dispatcher.Dispatch(func() {
 		defer func() {
-			context.Self.System.running.Remove(context.Self)
+			registry.transition(context.Self, Stopping)
+			// unsubscribe before closing the mailbox so that events don't become dead letters.
+			context.Self.System.eventStream.Unsubscribe(context.Self, nil)
+			context.stopReceiveTimeout()
+			context.closeMailbox()
+			context.stopChildren()
+			context.postStop()
+			context.detachFromParent()
+			context.notifyMonitors(Message{Down{
+				Cause: context.stopCause,
+				Actor: context.Self,
+			}})
+			context.stopMonitorForwarder()
+			context.publishLifecycle(ActorStopped{Actor: context.Self, Cause: context.stopCause})
+			close(context.done)
+			registry.transition(context.Self, Stopped)
 			context.Self.System.wg.Done()
-			context.closeAllChan()
-			context.Self.System.stopped.Add(context.Self)
 		}()
-		context.Self.System.running.Add(context.Self)
+		registry.transition(context.Self, Running)
 		<-startLatch
 		close(startLatch)
+		context.preStart()
+		context.resetReceiveTimeout()
+		context.publishLifecycle(ActorStarted{Actor: context.Self})
 		context.loop()
-	}()
+	})
 	return startLatch
 }
 
+// stopChildren stops all the children and waits for them to finish.
+//
+// Children are terminated gracefully when the actor was terminated, otherwise
+// they are killed.  Children failing meanwhile are stopped because a suspended
+// child never takes its PoisonPill.  When the stop timeout elapsed, it kills the
+// children still running and gives up waiting.
+func (context *ActorContext) stopChildren() {
+	children := context.Self.children.snapshot()
+	if len(children) == 0 {
+		return
+	}
+	for _, child := range children {
+		if context.stopCause == "terminated" {
+			child.context.terminate()
+		} else {
+			// kill blocks until the child takes it.  don't wait longer than the timeout.
+			go child.context.kill()
+		}
+	}
+	timeout := time.NewTimer(context.options.stopTimeout)
+	defer timeout.Stop()
+	for _, child := range children {
+		for stopped := false; !stopped; {
+			select {
+			case <-child.context.done:
+				stopped = true
+			case f := <-context.failureChan:
+				f.child.context.sendDirective(supervisorDirective{directive: Stop, cause: "stopped", reason: f.reason})
+			case <-timeout.C:
+				fmt.Fprintf(os.Stderr, "[%s] children didn't stop within %s: killing them\n", context.Self.Name, context.options.stopTimeout)
+				for _, child := range children {
+					go child.context.kill()
+				}
+				return
+			}
+		}
+	}
+}
+
+// detachFromParent frees the actor's name so that a new actor can take it.
+func (context *ActorContext) detachFromParent() {
+	self := context.Self
+	if self.parent == nil {
+		return
+	}
+	self.parent.children.Remove(self)
+	if self.parent == self.System.guardian {
+		self.System.topLevelActors.Remove(self)
+	}
+}
+
+// attachMonitor attaches mon, or sends Down to mon right away if the actor already stopped.
 func (context *ActorContext) attachMonitor(mon *Actor) {
-	context.attachMonChan <- mon
+	select {
+	case context.attachMonChan <- mon:
+	case <-context.done:
+		mon.Send(Message{Down{
+			Cause: context.stopCause,
+			Actor: context.Self,
+		}})
+	}
 }
 
 func (context *ActorContext) detachMonitor(mon *Actor) {
-	context.detachMonChan <- mon
+	select {
+	case context.detachMonChan <- mon:
+	case <-context.done:
+	}
 }
 
 func (context *ActorContext) kill() {
-	context.killChan <- kill{}
+	context.killWith("killed")
+}
+
+// killWith kills the actor with a cause reported by Down.
+func (context *ActorContext) killWith(cause string) {
+	select {
+	case context.killChan <- kill{cause: cause}:
+	case <-context.done:
+	}
 }
 
 func (context *ActorContext) terminate() {
-	context.mailbox <- Message{PoisonPill{}}
+	context.mailbox.enqueueSystem(envelope{message: Message{PoisonPill{}}})
 }
 
 // Actor's main loop which is executed in go routine
+//
+// An idle actor blocks on its control channels and mailbox at once.
+// Control messages are checked before the mailbox so that they take priority.
 func (context *ActorContext) loop() {
-	// TODO how monitor detect STARTED??
-	// now monitor can't detect STARTED.
-	// context.notifyMonitors(Message{STARTED})
 	for {
-		// TODO log
-		receiveTerminated := false
+		stop := false
 		select {
-		case <-context.killChan:
-			context.notifyMonitors(Message{Down{
-				Cause: "killed",
-				Actor: context.Self,
-			}})
-			context.Self.children.Do(func(child *Actor){
-				if child.IsRunning() {
-					child.context.kill()
-				}
-			})
-			return
+		case k := <-context.killChan:
+			stop = context.processKill(k)
 		case mon := <-context.attachMonChan:
-			if context.monitor == nil {
-				context.monitor = context.Self.System.spawnMonitorForwarderFor(context.Self)
-			}
-			context.monitor.Add(mon)
+			context.processAttachMonitor(mon)
 		case mon := <-context.detachMonChan:
 			context.monitor.Remove(mon)
-		case child := <-context.addChildChan:
-			context.Self.children.Add(child)
+		case f := <-context.failureChan:
+			context.supervise(f)
+		case directive := <-context.directiveChan:
+			stop = context.applyDirective(directive)
+		case m := <-context.forwarder.addChan():
+			context.forwarder.add(m.recipient)
+		case m := <-context.forwarder.delChan():
+			context.forwarder.unwatchAndDrop(m.recipient)
 		default:
-			if context.prePrecessHook!= nil {
-				context.prePrecessHook()
-			}
-			receiveTerminated = context.processOneMessage()
+			stop = context.waitAndProcess()
 		}
-		if receiveTerminated {
+		if stop {
 			return
 		}
-		// force give back control to goroutine scheduler.
-		runtime.Gosched()
 	}
 }
 
-func (context *ActorContext) processOneMessage() bool{
+// waitAndProcess blocks until a control message or a message arrives and processes it.
+// It returns true when the actor should stop.
+func (context *ActorContext) waitAndProcess() bool {
+	mailboxReady := context.mailbox.ready()
+	if context.suspended {
+		// suspended actor doesn't process messages until its supervisor decides.
+		mailboxReady = nil
+	}
 	select {
-	case msg := <-context.mailbox:
-		if len(msg) == 1 {
-			if _, ok := msg[0].(PoisonPill); ok {
-				context.notifyMonitors(Message{Down{
-					Cause: "terminated",
-					Actor: context.Self,
-				}})
-				context.Self.children.Do(func(child *Actor){
-					if child.IsRunning() {
-						child.context.terminate()
-					}
-				})
-				return true
-			} else {
-				context.currentBehavior(msg, context)
-			}
-		} else {
-			context.currentBehavior(msg, context)
+	case k := <-context.killChan:
+		return context.processKill(k)
+	case mon := <-context.attachMonChan:
+		context.processAttachMonitor(mon)
+	case mon := <-context.detachMonChan:
+		context.monitor.Remove(mon)
+	case f := <-context.failureChan:
+		context.supervise(f)
+	case directive := <-context.directiveChan:
+		return context.applyDirective(directive)
+	case m := <-context.forwarder.addChan():
+		context.forwarder.add(m.recipient)
+	case m := <-context.forwarder.delChan():
+		context.forwarder.unwatchAndDrop(m.recipient)
+	case <-mailboxReady:
+		if env, ok := context.mailbox.dequeue(); ok {
+			return context.processMessage(env)
 		}
-	case <-time.After(context.receiveTimeout):
-		// TODO log
-		// timeout
+	case <-context.receiveTimeoutChan():
+		context.processReceiveTimeout()
+	}
+	return false
+}
+
+func (context *ActorContext) processKill(k kill) bool {
+	return context.stopWith(k.cause)
+}
+
+// stopWith records the cause of the stop reported by Down.  It returns true so that the loop returns.
+func (context *ActorContext) stopWith(cause string) bool {
+	context.stopCause = cause
+	return true
+}
+
+func (context *ActorContext) processAttachMonitor(mon *Actor) {
+	if context.monitor == nil {
+		context.monitor = context.Self.System.spawnMonitorForwarderFor(context.Self)
+	}
+	context.monitor.Add(mon)
+}
+
+func (context *ActorContext) processMessage(env envelope) bool {
+	msg := env.message
+	if isPoisonPill(msg) {
+		return context.stopWith("terminated")
+	} else {
+		context.invoke(env)
+		context.resetReceiveTimeout()
 	}
 	return false
 }
@@ -233,3 +463,138 @@ func (context *ActorContext) notifyMonitors(msg Message) {
 		//TODO log
 	}
 }
+
+// stopMonitorForwarder stops the monitor forwarder after it forwards Down.
+//
+// It waits for the forwarder to stop so that Down is in monitors' mailboxes before
+// the actor is done.  Because a parent waits for its children to be done, monitors
+// of both receive the children's Down before the parent's.
+func (context *ActorContext) stopMonitorForwarder() {
+	if context.monitor == nil {
+		return
+	}
+	context.Self.System.monitorForwarders.Remove(context.monitor.Actor)
+	context.monitor.context.terminate()
+	<-context.monitor.context.done
+}
+
+// invoke calls current behavior with a given message.
+//
+// If the behavior panics, the actor suspends itself and reports the failure to its parent.
+func (context *ActorContext) invoke(env envelope) {
+	context.current = env
+	defer func() {
+		context.current = envelope{}
+	}()
+	defer context.recoverFailure(env.message)
+	context.receive(env.message, context)
+}
+
+// recoverFailure must be deferred.  It fails the actor if it is panicking.
+func (context *ActorContext) recoverFailure(msg Message) {
+	if r := recover(); r != nil {
+		logFailure(context.Self, r)
+		context.fail(r, msg)
+	}
+}
+
+func (context *ActorContext) fail(reason interface{}, msg Message) {
+	context.suspended = true
+	context.failedMessage = msg
+	parent := context.Self.parent
+	f := failure{child: context.Self, reason: reason, message: msg}
+	go func() {
+		defer logPanic(context.Self)
+		select {
+		case parent.context.failureChan <- f:
+		case <-parent.context.done:
+		}
+	}()
+}
+
+// supervise decides how to handle child's failure and sends the directive to children.
+func (context *ActorContext) supervise(f failure) {
+	strategy := context.strategy()
+	directive := strategy.decide(f.reason)
+	if directive == Escalate {
+		if context.Self.parent == nil {
+			// guardian has nobody to escalate to.
+			fmt.Fprintf(os.Stderr, "[%s] can't escalate failure of %s: stopping it\n", context.Self.Name, f.child.Name)
+			directive = Stop
+		} else {
+			context.escalated = append(context.escalated, f.child)
+			context.fail(f.reason, f.message)
+			return
+		}
+	}
+	cause := "stopped"
+	if directive == Restart && !context.permitRestart(strategy, f.child) {
+		fmt.Fprintf(os.Stderr, "[%s] %s exceeded restart limit: stopping it\n", context.Self.Name, f.child.Name)
+		directive = Stop
+		cause = "restart-limit"
+	}
+	for _, child := range strategy.targets(f.child, context.Self.children) {
+		if directive == Stop {
+			delete(context.restartStats, child)
+		}
+		child.context.sendDirective(supervisorDirective{directive: directive, cause: cause, reason: f.reason})
+	}
+}
+
+func (context *ActorContext) permitRestart(strategy *SupervisorStrategy, child *Actor) bool {
+	if context.restartStats == nil {
+		context.restartStats = make(map[*Actor]*restartStats)
+	}
+	stats, ok := context.restartStats[child]
+	if !ok {
+		stats = &restartStats{}
+		context.restartStats[child] = stats
+	}
+	return strategy.requestRestartPermission(stats, time.Now())
+}
+
+func (context *ActorContext) sendDirective(directive supervisorDirective) {
+	go func() {
+		defer logPanic(context.Self)
+		select {
+		case context.directiveChan <- directive:
+		case <-context.done:
+		}
+	}()
+}
+
+// applyDirective applies a directive sent from its supervisor.
+// It returns true when the actor should stop.
+func (context *ActorContext) applyDirective(d supervisorDirective) bool {
+	switch d.directive {
+	case Resume:
+	case Restart:
+		context.preRestart(d.reason, context.failedMessage)
+		context.restart()
+	case Stop:
+		return context.stopWith(d.cause)
+	}
+	context.suspended = false
+	context.failedMessage = nil
+	if d.directive == Restart {
+		context.postRestart(d.reason)
+		context.publishLifecycle(ActorRestarted{Actor: context.Self, Reason: d.reason})
+	}
+	// children which escalated their failure follow the decision.
+	for _, child := range context.escalated {
+		child.context.sendDirective(d)
+	}
+	context.escalated = nil
+	return false
+}
+
+// restart rebuilds the actor's state with a fresh receiver produced by its props.
+//
+// The behavior stack is reset and stashed messages are put back to the mailbox.
+// The mailbox, monitors and children are preserved, so that existing references
+// to the actor keep valid.
+func (context *ActorContext) restart() {
+	context.incarnate()
+	context.resetReceiveTimeout()
+	context.UnstashAll()
+}
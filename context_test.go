@@ -0,0 +1,242 @@
+package actor
+
+import (
+	"testing"
+	"time"
+)
+
+// inspector answers "children", "child" and "parent" from its context.
+func inspector(out chan interface{}) Receive {
+	return func(msg Message, context *ActorContext) {
+		switch msg[0] {
+		case "children":
+			out <- context.Children()
+		case "child":
+			out <- context.Child(msg[1].(string))
+		case "parent":
+			out <- context.Parent()
+		}
+	}
+}
+
+func receiveActors(t *testing.T, ch chan interface{}) []*Actor {
+	t.Helper()
+	v := <-ch
+	return v.([]*Actor)
+}
+
+func TestChildrenAndParent(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	parent := system.SpawnWithName("parent", inspector(out))
+	b := parent.SpawnWithName("b", inspector(out))
+	a := parent.SpawnWithName("a", nop)
+	c := parent.SpawnWithName("c", nop)
+
+	parent.Send(Message{"children"})
+	children := receiveActors(t, out)
+	if len(children) != 3 || children[0] != a || children[1] != b || children[2] != c {
+		t.Fatalf("children must be sorted by name: %v", children)
+	}
+	parent.Send(Message{"child", "b"})
+	expect(t, out, b)
+	parent.Send(Message{"child", "missing"})
+	expect(t, out, (*Actor)(nil))
+	b.Send(Message{"parent"})
+	expect(t, out, parent)
+	// top level actors have no parent visible to users.
+	parent.Send(Message{"parent"})
+	expect(t, out, (*Actor)(nil))
+
+	watchDown(system, c, out)
+	c.Terminate()
+	expect(t, out, "terminated")
+	parent.Send(Message{"children"})
+	if children := receiveActors(t, out); len(children) != 2 {
+		t.Fatalf("stopped children must not be included: %v", children)
+	}
+}
+
+func TestTopLevelActorsExcludeInternalActors(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	b := system.SpawnWithName("b", nop)
+	a := system.SpawnWithName("a", nop)
+	// monitors spawn internal forwarders.
+	watchDown(system, a, make(chan interface{}, 1))
+	eventually(t, func() bool {
+		return system.monitorForwarders.Len() == 1
+	})
+
+	actors := system.TopLevelActors()
+	// the watcher spawned by watchDown is a top level actor, too.
+	if len(actors) != 3 || actors[1] != a || actors[2] != b {
+		t.Fatalf("unexpected top level actors: %v", actors)
+	}
+}
+
+func TestChildFailingWhileParentStopsIsStopped(t *testing.T) {
+	system := NewActorSystem("test")
+	out := make(chan interface{}, 10)
+	started := make(chan interface{}, 1)
+	release := make(chan struct{})
+	parent := system.Spawn(nop)
+	child := parent.Spawn(func(msg Message, context *ActorContext) {
+		started <- msg[0]
+		<-release
+		panic("boom")
+	})
+	childDown := make(chan interface{}, 1)
+	watchDown(system, parent, out)
+	watchDown(system, child, childDown)
+
+	child.Send(Message{"block"})
+	expect(t, started, "block")
+	parent.Terminate()
+	eventually(t, func() bool {
+		return parent.State() == Stopping
+	})
+	// the child fails instead of taking its PoisonPill.
+	close(release)
+	expect(t, childDown, "stopped")
+	expect(t, out, "terminated")
+
+	shutdown := make(chan interface{})
+	go func() {
+		system.GracefulShutdown()
+		close(shutdown)
+	}()
+	select {
+	case <-shutdown:
+	case <-time.After(testTimeout):
+		t.Fatal("shutdown hung")
+	}
+}
+
+func TestChildrenAreKilledAfterStopTimeout(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	parent := system.SpawnProps(PropsFromReceive(nop).WithStopTimeout(50 * time.Millisecond))
+	// the child takes a second to reach its PoisonPill.
+	child := parent.Spawn(func(msg Message, context *ActorContext) {
+		time.Sleep(10 * time.Millisecond)
+	})
+	watchDown(system, child, out)
+	for i := 0; i < 100; i++ {
+		child.Send(Message{i})
+	}
+
+	parent.Terminate()
+	expect(t, out, "killed")
+}
+
+func TestMonitorForwarderStopsWithActor(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	actor := system.Spawn(nop)
+	watchDown(system, actor, out)
+	eventually(t, func() bool {
+		return system.monitorForwarders.Len() == 1
+	})
+	forwarder := system.monitorForwarders.snapshot()[0]
+
+	actor.Terminate()
+	expect(t, out, "terminated")
+	eventually(t, func() bool {
+		return forwarder.State() == Stopped
+	})
+	if n := system.monitorForwarders.Len(); n != 0 {
+		t.Fatalf("%d monitor forwarders remain", n)
+	}
+}
+
+func TestSenderIsNilAfterSend(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 1)
+	actor := system.Spawn(func(msg Message, context *ActorContext) {
+		out <- context.Sender() == nil
+	})
+
+	actor.Send(Message{"hello"})
+	expect(t, out, true)
+}
+
+func TestReplyReachesSender(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 2)
+	pong := system.Spawn(func(msg Message, context *ActorContext) {
+		context.Reply(Message{"pong"})
+	})
+	ping := system.Spawn(func(msg Message, context *ActorContext) {
+		switch msg[0] {
+		case "start":
+			context.Tell(pong, Message{"ping"})
+		case "pong":
+			out <- context.Sender() == pong
+		}
+	})
+
+	ping.Send(Message{"start"})
+	expect(t, out, true)
+}
+
+func TestReplyToUnknownSenderIsNoop(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 2)
+	actor := system.Spawn(func(msg Message, context *ActorContext) {
+		out <- context.Reply(Message{"reply"})
+	})
+
+	actor.Send(Message{"hello"})
+	expect(t, out, nil)
+	// the actor keeps processing messages.
+	actor.Send(Message{"hello"})
+	expect(t, out, nil)
+}
+
+func TestChildDownArrivesBeforeParentDown(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	watcher := system.Spawn(func(msg Message, context *ActorContext) {
+		if down, ok := msg[0].(Down); ok {
+			out <- down.Actor.Name
+		}
+	})
+	parent := system.SpawnWithName("parent", nop)
+	child := parent.SpawnWithName("child", nop)
+	grandchild := child.SpawnWithName("grandchild", nop)
+	// the parent is attached first, so the order can't come from attachment.
+	parent.context.attachMonitor(watcher)
+	child.context.attachMonitor(watcher)
+	grandchild.context.attachMonitor(watcher)
+
+	parent.Terminate()
+	expect(t, out, "grandchild")
+	expect(t, out, "child")
+	expect(t, out, "parent")
+}
+
+func TestMonitorOnStoppedActorReceivesDown(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	actor := system.Spawn(nop)
+	actor.Terminate()
+	eventually(t, func() bool {
+		return actor.State() == Stopped
+	})
+
+	actor.Monitor(system.Spawn(func(msg Message, context *ActorContext) {
+		if down, ok := msg[0].(Down); ok && down.Actor == actor {
+			out <- down.Cause
+		}
+	}))
+	expect(t, out, "terminated")
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+// +build !windows
+
+package actor
+
+import (
+	"syscall"
+	"time"
+)
+
+// cpuTime returns CPU time consumed by the process.
+func cpuTime() time.Duration {
+	var usage syscall.Rusage
+	syscall.Getrusage(syscall.RUSAGE_SELF, &usage)
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+}
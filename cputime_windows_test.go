@@ -0,0 +1,19 @@
+package actor
+
+import (
+	"syscall"
+	"time"
+)
+
+// cpuTime returns CPU time consumed by the process.
+func cpuTime() time.Duration {
+	var creation, exit, kernel, user syscall.Filetime
+	process, _ := syscall.GetCurrentProcess()
+	syscall.GetProcessTimes(process, &creation, &exit, &kernel, &user)
+	return filetimeDuration(kernel) + filetimeDuration(user)
+}
+
+// filetimeDuration converts a Filetime holding an interval in 100ns units.
+func filetimeDuration(ft syscall.Filetime) time.Duration {
+	return time.Duration(int64(ft.HighDateTime)<<32+int64(ft.LowDateTime)) * 100
+}
@@ -0,0 +1,36 @@
+package actor
+
+import "runtime"
+
+// Dispatcher runs actor's main loop.
+//
+// Dispatch is called once when the actor starts and must run the loop
+// asynchronously.  The loop returns when the actor stops.
+type Dispatcher interface {
+	Dispatch(loop func())
+}
+
+// DefaultDispatcher runs each actor's loop in its own goroutine.
+var DefaultDispatcher Dispatcher = goroutineDispatcher{}
+
+// PinnedDispatcher runs each actor's loop in its own goroutine locked to an OS thread.
+//
+// This is useful for actors which call thread sensitive libraries (e.g. OpenGL or cgo
+// libraries using thread local storage).
+var PinnedDispatcher Dispatcher = pinnedDispatcher{}
+
+type goroutineDispatcher struct{}
+
+func (goroutineDispatcher) Dispatch(loop func()) {
+	go loop()
+}
+
+type pinnedDispatcher struct{}
+
+func (pinnedDispatcher) Dispatch(loop func()) {
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		loop()
+	}()
+}
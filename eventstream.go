@@ -0,0 +1,147 @@
+package actor
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ErrNilEventType is the panic value of Subscribe called with nil eventType.
+var ErrNilEventType = errors.New("actor: nil event type")
+
+// ActorStarted is published to EventStream when an actor started.
+type ActorStarted struct {
+	Actor *Actor
+}
+
+// ActorStopped is published to EventStream when an actor stopped.
+// Cause is the same as Down's.
+type ActorStopped struct {
+	Actor *Actor
+	Cause string
+}
+
+// ActorRestarted is published to EventStream when an actor was restarted by its supervisor.
+type ActorRestarted struct {
+	Actor  *Actor
+	Reason interface{}
+}
+
+// EventStream is a publish/subscribe bus of an actor system.
+//
+// Actors subscribe to events by Go type, and receive published events as
+// Message{event}.  The actor system publishes ActorStarted, ActorStopped,
+// ActorRestarted and DeadLetter to it.  Subscriptions are removed automatically
+// when the subscriber stops.  EventStream is safe for concurrent use.
+type EventStream struct {
+	lock          sync.RWMutex
+	subscriptions map[*Actor][]reflect.Type
+}
+
+func newEventStream() *EventStream {
+	return &EventStream{subscriptions: make(map[*Actor][]reflect.Type)}
+}
+
+// EventStream returns the event stream of the actor system.
+func (system *ActorSystem) EventStream() *EventStream {
+	return system.eventStream
+}
+
+// Subscribe subscribes an actor to events assignable to a given type.
+//
+// Subscribing to an interface type receives all the events implementing it
+// (reflect.TypeOf((*interface{})(nil)).Elem() for all the events).
+// Stopping or stopped actors can't subscribe.  It panics with ErrNilEventType
+// if eventType is nil.
+// For example,
+//   stream := system.EventStream()
+//   stream.Subscribe(logger, reflect.TypeOf(actor.DeadLetter{}))
+//   stream.Subscribe(auditor, reflect.TypeOf((*DomainEvent)(nil)).Elem())
+func (stream *EventStream) Subscribe(subscriber *Actor, eventType reflect.Type) {
+	if eventType == nil {
+		panic(ErrNilEventType)
+	}
+	stream.lock.Lock()
+	defer stream.lock.Unlock()
+	// checked under the lock so that it can't be subscribed after it unsubscribed while stopping.
+	if state := subscriber.State(); state == Stopping || state == Stopped {
+		return
+	}
+	for _, t := range stream.subscriptions[subscriber] {
+		if t == eventType {
+			return
+		}
+	}
+	stream.subscriptions[subscriber] = append(stream.subscriptions[subscriber], eventType)
+}
+
+// Unsubscribe unsubscribes an actor from a given type.
+//
+// Nil eventType unsubscribes the actor from all the types.
+func (stream *EventStream) Unsubscribe(subscriber *Actor, eventType reflect.Type) {
+	stream.lock.Lock()
+	defer stream.lock.Unlock()
+	if eventType == nil {
+		delete(stream.subscriptions, subscriber)
+		return
+	}
+	types := stream.subscriptions[subscriber]
+	for i, t := range types {
+		if t == eventType {
+			types = append(types[:i:i], types[i+1:]...)
+			break
+		}
+	}
+	if len(types) == 0 {
+		delete(stream.subscriptions, subscriber)
+	} else {
+		stream.subscriptions[subscriber] = types
+	}
+}
+
+// Publish sends an event to all the actors subscribing to its type.
+//
+// Each subscriber receives the event once even if it subscribes to several matching types.
+func (stream *EventStream) Publish(event interface{}) {
+	stream.publish(event, nil)
+}
+
+// publish skips a given actor so that a dead letter of a subscriber isn't sent to itself again.
+func (stream *EventStream) publish(event interface{}, skip *Actor) {
+	if event == nil {
+		return
+	}
+	for _, subscriber := range stream.subscribersOf(reflect.TypeOf(event), skip) {
+		subscriber.Send(Message{event})
+	}
+}
+
+// subscribersOf returns subscribers of a given event type except skip.
+func (stream *EventStream) subscribersOf(eventType reflect.Type, skip *Actor) []*Actor {
+	stream.lock.RLock()
+	defer stream.lock.RUnlock()
+	subscribers := []*Actor{}
+	for subscriber, types := range stream.subscriptions {
+		if subscriber == skip {
+			continue
+		}
+		for _, t := range types {
+			if eventType.AssignableTo(t) {
+				subscribers = append(subscribers, subscriber)
+				break
+			}
+		}
+	}
+	return subscribers
+}
+
+// publishLifecycle publishes lifecycle events of user's actors.  Events of internal
+// actors are not published because subscribers watching them would create more of them.
+func (context *ActorContext) publishLifecycle(event interface{}) {
+	self := context.Self
+	if self.parent == nil || strings.HasPrefix(self.Name, "$") {
+		return
+	}
+	self.System.eventStream.Publish(event)
+}
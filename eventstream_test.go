@@ -0,0 +1,115 @@
+package actor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testEvent interface {
+	isTestEvent()
+}
+
+type orderPlaced struct {
+	ID string
+}
+
+func (orderPlaced) isTestEvent() {}
+
+func TestEventStreamPublishesByType(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	subscriber := system.Spawn(func(msg Message, context *ActorContext) {
+		out <- msg[0]
+	})
+	stream := system.EventStream()
+	stream.Subscribe(subscriber, reflect.TypeOf(orderPlaced{}))
+	stream.Subscribe(subscriber, reflect.TypeOf((*testEvent)(nil)).Elem())
+
+	stream.Publish("ignored")
+	stream.Publish(orderPlaced{ID: "1"})
+	// matching several types delivers the event once.
+	expect(t, out, orderPlaced{ID: "1"})
+	expectNothing(t, out, 50*time.Millisecond)
+
+	stream.Unsubscribe(subscriber, nil)
+	stream.Publish(orderPlaced{ID: "2"})
+	expectNothing(t, out, 50*time.Millisecond)
+}
+
+func TestStoppingActorCantSubscribe(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	stream := system.EventStream()
+	actor := system.Spawn(nop, WithLifecycleHooks(LifecycleHooks{
+		PostStop: func(context *ActorContext) {
+			stream.Subscribe(context.Self, reflect.TypeOf(orderPlaced{}))
+		},
+	}))
+	watchDown(system, actor, out)
+
+	actor.Terminate()
+	expect(t, out, "terminated")
+	stream.Subscribe(actor, reflect.TypeOf(orderPlaced{}))
+
+	stream.lock.RLock()
+	defer stream.lock.RUnlock()
+	if _, ok := stream.subscriptions[actor]; ok {
+		t.Fatal("stopping or stopped actor must not be subscribed")
+	}
+}
+
+func TestSubscribeRejectsNilType(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	subscriber := system.Spawn(func(msg Message, context *ActorContext) {
+		out <- msg[0]
+	})
+	stream := system.EventStream()
+	func() {
+		defer func() {
+			if r := recover(); r != ErrNilEventType {
+				t.Fatalf("expected ErrNilEventType, but got %v", r)
+			}
+		}()
+		stream.Subscribe(subscriber, nil)
+	}()
+
+	// the stream keeps working.
+	stream.Subscribe(subscriber, reflect.TypeOf((*interface{})(nil)).Elem())
+	stream.Publish("any")
+	expect(t, out, "any")
+	stream.Unsubscribe(subscriber, nil)
+}
+
+func TestLifecycleEvents(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	subscriber := system.Spawn(func(msg Message, context *ActorContext) {
+		switch e := msg[0].(type) {
+		case ActorStarted:
+			out <- "started " + e.Actor.Name
+		case ActorRestarted:
+			out <- "restarted " + e.Actor.Name
+		case ActorStopped:
+			out <- "stopped " + e.Actor.Name + " " + e.Cause
+		}
+	})
+	stream := system.EventStream()
+	stream.Subscribe(subscriber, reflect.TypeOf(ActorStarted{}))
+	stream.Subscribe(subscriber, reflect.TypeOf(ActorRestarted{}))
+	stream.Subscribe(subscriber, reflect.TypeOf(ActorStopped{}))
+
+	actor := system.SpawnWithName("worker", func(msg Message, context *ActorContext) {
+		panic("boom")
+	})
+	expect(t, out, "started worker")
+	actor.Send(Message{"boom"})
+	expect(t, out, "restarted worker")
+	actor.Terminate()
+	expect(t, out, "stopped worker terminated")
+}
@@ -13,9 +13,9 @@ func main() {
 
 	system := actor.NewActorSystem("ping-pong")
 	pong := func(msg actor.Message, context *actor.ActorContext) {
-		fmt.Printf("%s received: %s\n", context.Self.Name, msg[1])
+		fmt.Printf("%s received: %s\n", context.Self.Name, msg[0])
 		fmt.Printf("%s sends : Pong\n", context.Self.Name)
-		msg[0].(*actor.Actor).Send(actor.Message{"Pong"})
+		context.Reply(actor.Message{"Pong"})
 	}
 	ping := func(_ponger *actor.Actor) actor.Receive {
 		return func(msg actor.Message, context *actor.ActorContext) {
@@ -23,7 +23,7 @@ func main() {
 				fmt.Printf("%s receives: Pong.\nPing-Pong finished.\n", context.Self.Name)
 			} else {
 				fmt.Printf("%s sends : Ping\n", context.Self.Name)
-				_ponger.Send(actor.Message{context.Self, "Ping"})
+				context.Tell(_ponger, actor.Message{"Ping"})
 			}
 		}
 	}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	actor "github.com/everpeace/go-actor"
+)
+
+func main() {
+	fmt.Println("==========================================================")
+	fmt.Println("== Pool example")
+	fmt.Println("== A pool spawns its workers as its children and routes")
+	fmt.Println("== messages to them.  In this example, \"echo\" pool has")
+	fmt.Println("== 2 workers and sends messages to them in round-robin.")
+
+	system := actor.NewActorSystem("pool")
+	echo := func(msg actor.Message, context *actor.ActorContext) {
+		fmt.Printf("%s : %v\n", context.Self.ActorPath(), msg)
+	}
+
+	pool := system.SpawnPool("echo", 2, actor.PropsFromReceive(echo), actor.RoundRobin)
+	for i := 0; i < 4; i++ {
+		fmt.Printf("Sent [hello %d] to \"echo\"\n", i)
+		pool.Send(actor.Message{"hello", i})
+	}
+
+	system.GracefulShutdownIn(time.Duration(1) * time.Second)
+	fmt.Println("==========================================================")
+}
@@ -1,12 +1,68 @@
 package actor
 
-import "github.com/dropbox/godropbox/container/set"
+import "sync"
 
+// ForwardingActor forwards messages to its recipients.
+//
+// Which recipients receive a message is decided by its RoutingStrategy.
+// ForwardingActor spawned by SpawnForwardActor forwards to all of them.
+// ForwardingActor watches its recipients and removes them when they stop.
 type ForwardingActor struct {
 	*Actor
 	addRecipientChan chan addRecipient
 	delRecipientChan chan removeRecipient
-	recipients set.Set
+	// recipients are modified only in the actor's goroutine.  lock guards them
+	// from Recipients called by other goroutines.
+	lock            sync.RWMutex
+	recipients      []*Actor
+	logic           routingLogic
+	watchRecipients bool
+}
+
+// RecipientsEmpty is sent to monitors of a ForwardingActor when its last
+// recipient stopped or was removed.
+//
+// The forwarding actor keeps running and publishes messages to DeadLetters
+// until a recipient is added.  Monitors can stop it or alert on this event.
+type RecipientsEmpty struct {
+	Forwarder *ForwardingActor
+}
+
+// spawnForwardActor creates a ForwardingActor by newActor (newChildActor or newTopLevelActor) and starts it.
+//
+// It panics if the name is already used.
+func spawnForwardActor(newActor func(props Props) (*Actor, error), name string, actors []*Actor, options ...SpawnOption) *ForwardingActor {
+	return spawnRouter(newActor, name, Broadcast, actors, options...)
+}
+
+// spawnRouter is the same as spawnForwardActor except that it takes a routing strategy.
+func spawnRouter(newActor func(props Props) (*Actor, error), name string, strategy RoutingStrategy, actors []*Actor, options ...SpawnOption) *ForwardingActor {
+	forwardActor := newForwardingActor(strategy, actors)
+	return forwardActor.start(newActor, PropsFromReceive(forwardActor.receive()).WithName(name).WithOptions(options...))
+}
+
+func newForwardingActor(strategy RoutingStrategy, actors []*Actor) *ForwardingActor {
+	forwardActor := &ForwardingActor{
+		addRecipientChan: make(chan addRecipient),
+		delRecipientChan: make(chan removeRecipient),
+		logic: strategy.newRoutingLogic(),
+		watchRecipients: true,
+	}
+	for _, actor := range actors {
+		forwardActor.add(actor)
+	}
+	return forwardActor
+}
+
+// start creates the actor from props by newActor and starts it.
+func (actor *ForwardingActor) start(newActor func(props Props) (*Actor, error), props Props) *ForwardingActor {
+	actor.Actor = mustActor(newActor(props))
+	actor.context.forwarder = actor
+	for _, recipient := range actor.recipients {
+		actor.watch(recipient)
+	}
+	spawn(actor.Actor)
+	return actor
 }
 
 // internal message used in ForwardingActor
@@ -17,36 +73,128 @@ type removeRecipient struct {
 	recipient *Actor
 }
 
+// Add adds a recipient asynchronously.
 func (actor *ForwardingActor) Add(recipient *Actor) {
-	actor.addRecipientChan <- addRecipient{recipient: recipient}
+	select {
+	case actor.addRecipientChan <- addRecipient{recipient: recipient}:
+	case <-actor.context.done:
+	}
+}
+
+// Recipients returns a snapshot of the recipients.
+func (actor *ForwardingActor) Recipients() []*Actor {
+	actor.lock.RLock()
+	defer actor.lock.RUnlock()
+	recipients := make([]*Actor, len(actor.recipients))
+	copy(recipients, actor.recipients)
+	return recipients
 }
 
+// Remove removes a recipient asynchronously.
 func (actor *ForwardingActor) Remove(recipient *Actor) {
-	actor.delRecipientChan <- removeRecipient{recipient: recipient}
+	select {
+	case actor.delRecipientChan <- removeRecipient{recipient: recipient}:
+	case <-actor.context.done:
+	}
+}
+
+// addChan and delChan return nil for non forwarding actors so that select ignores them.
+func (actor *ForwardingActor) addChan() chan addRecipient {
+	if actor == nil {
+		return nil
+	}
+	return actor.addRecipientChan
+}
+
+func (actor *ForwardingActor) delChan() chan removeRecipient {
+	if actor == nil {
+		return nil
+	}
+	return actor.delRecipientChan
 }
 
-func (actor *ForwardingActor) preProcessHook() func(){
-	return func() {
-		select{
-		case m := <-actor.addRecipientChan:
-			actor.recipients.Add(m.recipient)
-		case m := <-actor.delRecipientChan:
-			actor.recipients.Remove(m.recipient)
-		default:
+// add and remove must be called in the actor's goroutine (or before it starts).
+func (actor *ForwardingActor) add(recipient *Actor) {
+	for _, r := range actor.recipients {
+		if r == recipient {
+			return
 		}
 	}
+	actor.lock.Lock()
+	actor.recipients = append(actor.recipients, recipient)
+	actor.lock.Unlock()
+	actor.recipientsChanged()
+	if actor.Actor != nil {
+		actor.watch(recipient)
+	}
+}
+
+// remove returns true if the recipient was removed.
+func (actor *ForwardingActor) remove(recipient *Actor) bool {
+	for i, r := range actor.recipients {
+		if r == recipient {
+			actor.lock.Lock()
+			actor.recipients = append(actor.recipients[:i:i], actor.recipients[i+1:]...)
+			actor.lock.Unlock()
+			actor.recipientsChanged()
+			return true
+		}
+	}
+	return false
+}
+
+// drop removes the recipient and notifies monitors when no recipient remains.
+// It returns true if the recipient was removed.
+func (actor *ForwardingActor) drop(recipient *Actor) bool {
+	if !actor.remove(recipient) {
+		return false
+	}
+	if len(actor.recipients) == 0 {
+		actor.context.notifyMonitors(Message{RecipientsEmpty{Forwarder: actor}})
+	}
+	return true
+}
+
+// unwatchAndDrop is called when a recipient is removed by Remove.
+func (actor *ForwardingActor) unwatchAndDrop(recipient *Actor) {
+	if actor.watchRecipients {
+		recipient.Demonitor(actor.Actor)
+	}
+	actor.drop(recipient)
+}
+
+func (actor *ForwardingActor) watch(recipient *Actor) {
+	if actor.watchRecipients {
+		recipient.Monitor(actor.Actor)
+	}
+}
+
+func (actor *ForwardingActor) recipientsChanged() {
+	if observer, ok := actor.logic.(recipientsObserver); ok {
+		observer.recipientsChanged(actor.recipients)
+	}
 }
 
 func (actor *ForwardingActor) receive() Receive {
 	return func(msg Message, context *ActorContext) {
-		actor.recipients.Do(func(e interface{}) {
-			if a, ok := e.(*Actor); ok {
-				a.Send(msg)
-			}
-			if a, ok := e.(*ForwardingActor); ok {
-				a.Send(msg)
+		if len(msg) == 1 && actor.watchRecipients {
+			if down, ok := msg[0].(Down); ok && actor.drop(down.Actor) {
+				// the recipient stopped.
+				return
 			}
-		})
+		}
+		recipients := actor.logic.route(msg, actor.recipients)
+		if len(recipients) == 0 {
+			context.Self.System.publishDeadLetter(DeadLetter{
+				Message:   msg,
+				Sender:    context.Sender(),
+				Recipient: context.Self,
+			})
+			return
+		}
+		for _, recipient := range recipients {
+			recipient.SendFrom(msg, context.Sender())
+		}
 	}
 }
 
@@ -0,0 +1,109 @@
+package actor
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrAskTimeout is the error which a future of Ask completes with when no reply arrived in time.
+var ErrAskTimeout = errors.New("actor: ask timed out")
+
+// Future is a placeholder of a reply which will arrive later.
+type Future struct {
+	lock      sync.Mutex
+	done      chan struct{}
+	result    Message
+	err       error
+	callbacks []func(Message, error)
+}
+
+// Ask sends a message to the target and returns a future of its reply.
+//
+// Ask spawns a temporary actor which receives the reply.  The temporary actor
+// is delivered as the sender of the message so that the target can reply to it
+// by context.Reply().  The future completes with ErrAskTimeout if no reply
+// arrived within a given timeout, or with the error returned by SendFrom.
+// For example,
+//   echo := system.Spawn(func(msg Message, context *ActorContext){
+//     context.Reply(msg)
+//   })
+//   reply, err := actor.Ask(echo, Message{"hello"}, time.Second).Await()
+//   // reply ==> Message{"hello"}
+func Ask(target *Actor, msg Message, timeout time.Duration) *Future {
+	future := newFuture()
+	replyTo := target.System.spawnTemporaryActor(func(reply Message, context *ActorContext) {
+		future.complete(reply, nil)
+		context.Self.Terminate()
+	})
+	timer := time.AfterFunc(timeout, func() {
+		if future.complete(nil, ErrAskTimeout) {
+			replyTo.Terminate()
+		}
+	})
+	future.OnComplete(func(Message, error) {
+		timer.Stop()
+	})
+	if err := target.SendFrom(msg, replyTo); err != nil {
+		if future.complete(nil, err) {
+			replyTo.Terminate()
+		}
+	}
+	return future
+}
+
+func newFuture() *Future {
+	return &Future{done: make(chan struct{})}
+}
+
+// Await blocks until the future completes and returns its result.
+func (future *Future) Await() (Message, error) {
+	<-future.done
+	return future.result, future.err
+}
+
+// OnComplete registers a callback which is called asynchronously when the future completes.
+//
+// If the future has already completed, the callback is called immediately.
+func (future *Future) OnComplete(callback func(result Message, err error)) {
+	future.lock.Lock()
+	defer future.lock.Unlock()
+	select {
+	case <-future.done:
+		go callback(future.result, future.err)
+	default:
+		future.callbacks = append(future.callbacks, callback)
+	}
+}
+
+// PipeTo sends the result of the future to a given actor when it completes.
+//
+// If the future failed, the actor receives Message{err}.
+func (future *Future) PipeTo(actor *Actor) {
+	future.OnComplete(func(result Message, err error) {
+		if err != nil {
+			actor.Send(Message{err})
+		} else {
+			actor.Send(result)
+		}
+	})
+}
+
+// complete completes the future and returns false when it has already completed.
+func (future *Future) complete(result Message, err error) bool {
+	future.lock.Lock()
+	defer future.lock.Unlock()
+	select {
+	case <-future.done:
+		return false
+	default:
+	}
+	future.result = result
+	future.err = err
+	close(future.done)
+	for _, callback := range future.callbacks {
+		go callback(result, err)
+	}
+	future.callbacks = nil
+	return true
+}
@@ -0,0 +1,83 @@
+package actor
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAskReceivesReply(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	echo := system.Spawn(func(msg Message, context *ActorContext) {
+		context.Reply(msg)
+	})
+
+	reply, err := Ask(echo, Message{"hello"}, testTimeout).Await()
+	if err != nil {
+		t.Fatalf("expected a reply, but got %v", err)
+	}
+	if len(reply) != 1 || reply[0] != "hello" {
+		t.Fatalf("expected Message{hello}, but got %v", reply)
+	}
+}
+
+func TestAskTimesOutAndStopsTemporaryActor(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	senders := make(chan *Actor, 1)
+	silent := system.Spawn(func(msg Message, context *ActorContext) {
+		senders <- context.Sender()
+	})
+
+	_, err := Ask(silent, Message{"hello"}, 10*time.Millisecond).Await()
+	if err != ErrAskTimeout {
+		t.Fatalf("expected ErrAskTimeout, but got %v", err)
+	}
+	var replyTo *Actor
+	select {
+	case replyTo = <-senders:
+	case <-time.After(testTimeout):
+		t.Fatal("timed out waiting for the message")
+	}
+	if replyTo == nil {
+		t.Fatal("Ask must deliver the temporary actor as the sender")
+	}
+	eventually(t, func() bool {
+		return replyTo.State() == Stopped
+	})
+	if system.guardian.children.Get(replyTo.Name) != nil {
+		t.Fatalf("%s must be removed from the guardian after the timeout", replyTo.Name)
+	}
+}
+
+func TestOnCompleteAfterCompletion(t *testing.T) {
+	future := newFuture()
+	if !future.complete(Message{"done"}, nil) {
+		t.Fatal("first complete must succeed")
+	}
+	if future.complete(Message{"again"}, nil) {
+		t.Fatal("second complete must be ignored")
+	}
+
+	out := make(chan interface{}, 1)
+	future.OnComplete(func(result Message, err error) {
+		out <- result[0]
+	})
+	expect(t, out, "done")
+}
+
+func TestPipeToDeliversError(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 1)
+	receiver := system.Spawn(func(msg Message, context *ActorContext) {
+		out <- msg[0]
+	})
+
+	failure := errors.New("failure")
+	future := newFuture()
+	future.PipeTo(receiver)
+	future.complete(nil, failure)
+	expect(t, out, failure)
+}
@@ -0,0 +1,87 @@
+package actor
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// defaultVirtualNodes is the number of virtual nodes per routee when not specified.
+const defaultVirtualNodes = 100
+
+// ConsistentHashing forwards messages with the same key to the same routee.
+//
+// key extracts the key (e.g. user ID) from a message.  Routees are placed on a
+// hash ring with virtualNodes points each (100 if not positive), so that only a
+// small portion of keys move to other routees when routees are added or removed.
+// Routees are placed by their actor path, thus a routee replaced by an actor
+// with the same path takes over its keys.
+// For example,
+//   router := system.SpawnRouter("orders", actor.ConsistentHashing(func(msg Message) string {
+//     return msg[0].(Order).ID
+//   }, 0), worker1, worker2)
+func ConsistentHashing(key func(msg Message) string, virtualNodes int) RoutingStrategy {
+	if virtualNodes <= 0 {
+		virtualNodes = defaultVirtualNodes
+	}
+	return routingStrategyFunc(func() routingLogic {
+		return &consistentHashLogic{key: key, virtualNodes: virtualNodes}
+	})
+}
+
+type consistentHashLogic struct {
+	key          func(msg Message) string
+	virtualNodes int
+	ring         hashRing
+}
+
+type hashRing []hashRingNode
+
+type hashRingNode struct {
+	hash   uint32
+	routee *Actor
+}
+
+func (logic *consistentHashLogic) recipientsChanged(routees []*Actor) {
+	ring := make(hashRing, 0, len(routees)*logic.virtualNodes)
+	for _, routee := range routees {
+		path := routee.ActorPath()
+		for i := 0; i < logic.virtualNodes; i++ {
+			ring = append(ring, hashRingNode{hash: hashKey(path + "#" + strconv.Itoa(i)), routee: routee})
+		}
+	}
+	sort.Sort(ring)
+	logic.ring = ring
+}
+
+func (logic *consistentHashLogic) route(msg Message, routees []*Actor) []*Actor {
+	if len(logic.ring) == 0 {
+		return nil
+	}
+	h := hashKey(logic.key(msg))
+	i := sort.Search(len(logic.ring), func(i int) bool {
+		return logic.ring[i].hash >= h
+	})
+	if i == len(logic.ring) {
+		i = 0
+	}
+	return []*Actor{logic.ring[i].routee}
+}
+
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32()
+}
+
+func (ring hashRing) Len() int {
+	return len(ring)
+}
+
+func (ring hashRing) Less(i, j int) bool {
+	return ring[i].hash < ring[j].hash
+}
+
+func (ring hashRing) Swap(i, j int) {
+	ring[i], ring[j] = ring[j], ring[i]
+}
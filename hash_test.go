@@ -0,0 +1,99 @@
+package actor
+
+import (
+	"strconv"
+	"testing"
+)
+
+func firstElementKey(msg Message) string {
+	return msg[0].(string)
+}
+
+func routeKeys(logic routingLogic, routees []*Actor, keys int) map[string]*Actor {
+	if observer, ok := logic.(recipientsObserver); ok {
+		observer.recipientsChanged(routees)
+	}
+	routed := map[string]*Actor{}
+	for i := 0; i < keys; i++ {
+		key := "key" + strconv.Itoa(i)
+		routed[key] = logic.route(Message{key}, routees)[0]
+	}
+	return routed
+}
+
+func TestConsistentHashingIsStable(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	routees := spawnRoutees(system, 3)
+	logic := ConsistentHashing(firstElementKey, 0).newRoutingLogic()
+	before := routeKeys(logic, routees, 1000)
+	again := routeKeys(logic, routees, 1000)
+	for key, routee := range before {
+		if again[key] != routee {
+			t.Fatalf("%s moved without change of routees", key)
+		}
+	}
+	used := map[*Actor]bool{}
+	for _, routee := range before {
+		used[routee] = true
+	}
+	if len(used) != 3 {
+		t.Fatalf("keys must be spread over all the routees: %d", len(used))
+	}
+}
+
+func TestConsistentHashingMovesFewKeysWhenRouteeAdded(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	routees := spawnRoutees(system, 4)
+	logic := ConsistentHashing(firstElementKey, 0).newRoutingLogic()
+	before := routeKeys(logic, routees[:3], 1000)
+	after := routeKeys(logic, routees, 1000)
+
+	moved := 0
+	for key, routee := range before {
+		if after[key] == routee {
+			continue
+		}
+		if after[key] != routees[3] {
+			t.Fatalf("%s moved to an existing routee", key)
+		}
+		moved++
+	}
+	// a quarter of keys are expected to move.
+	if moved == 0 || moved > 400 {
+		t.Fatalf("%d of 1000 keys moved", moved)
+	}
+
+	// removing the routee moves its keys back.
+	restored := routeKeys(logic, routees[:3], 1000)
+	for key, routee := range before {
+		if restored[key] != routee {
+			t.Fatalf("%s didn't return to its routee", key)
+		}
+	}
+}
+
+func TestConsistentHashingReplacedRouteeTakesOverKeys(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 1)
+	routees := spawnRoutees(system, 2)
+	worker := system.SpawnWithName("worker", nop)
+	logic := ConsistentHashing(firstElementKey, 0).newRoutingLogic()
+	before := routeKeys(logic, append(routees, worker), 1000)
+
+	watchDown(system, worker, out)
+	worker.Terminate()
+	expect(t, out, "terminated")
+	replaced := system.SpawnWithName("worker", nop)
+	after := routeKeys(logic, append(routees, replaced), 1000)
+	for key, routee := range before {
+		if routee == worker {
+			routee = replaced
+		}
+		if after[key] != routee {
+			t.Fatalf("%s moved when its routee was replaced", key)
+		}
+	}
+}
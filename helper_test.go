@@ -0,0 +1,59 @@
+package actor
+
+import (
+	"testing"
+	"time"
+)
+
+// testTimeout bounds every wait in tests so that a broken actor fails the test instead of hanging it.
+const testTimeout = 2 * time.Second
+
+// expect receives a value from ch and fails the test unless it equals want.
+func expect(t *testing.T, ch <-chan interface{}, want interface{}) {
+	t.Helper()
+	select {
+	case got := <-ch:
+		if got != want {
+			t.Fatalf("expected %v, but got %v", want, got)
+		}
+	case <-time.After(testTimeout):
+		t.Fatalf("timed out waiting for %v", want)
+	}
+}
+
+// expectNothing fails the test if ch receives a value within d.
+func expectNothing(t *testing.T, ch <-chan interface{}, d time.Duration) {
+	t.Helper()
+	select {
+	case got := <-ch:
+		t.Fatalf("expected nothing, but got %v", got)
+	case <-time.After(d):
+	}
+}
+
+// eventually polls cond until it holds.
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(testTimeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition was not satisfied in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// watchDown reports the causes of the target's Down messages to out.
+//
+// The monitor is attached synchronously, so that the Down can't be missed.
+func watchDown(system *ActorSystem, target *Actor, out chan interface{}) {
+	mon := system.Spawn(func(msg Message, context *ActorContext) {
+		if down, ok := msg[0].(Down); ok && down.Actor == target {
+			out <- down.Cause
+		}
+	})
+	target.context.attachMonitor(mon)
+}
+
+// nop is a behavior which ignores every message.
+func nop(msg Message, context *ActorContext) {}
@@ -0,0 +1,103 @@
+package actor
+
+// LifecycleHooks are called in the actor's goroutine at the moments of its lifecycle.
+//
+// PreStart is called before the actor processes its first message.
+// PostStop is called after the actor and its children stopped (terminated, killed or
+// stopped by its supervisor), and before its monitors receive Down.
+// PreRestart is called before the actor is restarted with the failure reason and
+// the message being processed when it failed (nil if it was restarted with its sibling).
+// PostRestart is called after the actor was restarted.
+// Any of them can be nil.
+//
+// For example,
+//   system.Spawn(receive, actor.WithLifecycleHooks(actor.LifecycleHooks{
+//     PreStart: func(context *ActorContext) {
+//       file, _ = os.Open("data.txt")
+//     },
+//     PostStop: func(context *ActorContext) {
+//       file.Close()
+//     },
+//   }))
+type LifecycleHooks struct {
+	PreStart    func(context *ActorContext)
+	PostStop    func(context *ActorContext)
+	PreRestart  func(context *ActorContext, reason interface{}, msg Message)
+	PostRestart func(context *ActorContext, reason interface{})
+}
+
+// PreStarter, PostStopper, PreRestarter and PostRestarter can be implemented by
+// Receiver to hook its lifecycle.  They take priority over LifecycleHooks option.
+//
+// When the actor restarts, PreRestart is called on the failed instance and
+// PostRestart is called on the fresh instance.
+type PreStarter interface {
+	PreStart(context *ActorContext)
+}
+type PostStopper interface {
+	PostStop(context *ActorContext)
+}
+type PreRestarter interface {
+	PreRestart(context *ActorContext, reason interface{}, msg Message)
+}
+type PostRestarter interface {
+	PostRestart(context *ActorContext, reason interface{})
+}
+
+// WithLifecycleHooks spawns an actor with given lifecycle hooks.
+func WithLifecycleHooks(hooks LifecycleHooks) SpawnOption {
+	return func(o *spawnOptions) {
+		o.hooks = hooks
+	}
+}
+
+// lifecycleHooksOf overrides hooks by the methods which the receiver implements.
+func lifecycleHooksOf(receiver Receiver, hooks LifecycleHooks) LifecycleHooks {
+	if r, ok := receiver.(PreStarter); ok {
+		hooks.PreStart = r.PreStart
+	}
+	if r, ok := receiver.(PostStopper); ok {
+		hooks.PostStop = r.PostStop
+	}
+	if r, ok := receiver.(PreRestarter); ok {
+		hooks.PreRestart = r.PreRestart
+	}
+	if r, ok := receiver.(PostRestarter); ok {
+		hooks.PostRestart = r.PostRestart
+	}
+	return hooks
+}
+
+// panics in PreStart and PostRestart fail the actor as well as in message handler.
+func (context *ActorContext) preStart() {
+	if context.hooks.PreStart == nil {
+		return
+	}
+	defer context.recoverFailure(nil)
+	context.hooks.PreStart(context)
+}
+
+func (context *ActorContext) postRestart(reason interface{}) {
+	if context.hooks.PostRestart == nil {
+		return
+	}
+	defer context.recoverFailure(nil)
+	context.hooks.PostRestart(context, reason)
+}
+
+// panics in PostStop and PreRestart are just logged.
+func (context *ActorContext) postStop() {
+	if context.hooks.PostStop == nil {
+		return
+	}
+	defer logPanic(context.Self)
+	context.hooks.PostStop(context)
+}
+
+func (context *ActorContext) preRestart(reason interface{}, msg Message) {
+	if context.hooks.PreRestart == nil {
+		return
+	}
+	defer logPanic(context.Self)
+	context.hooks.PreRestart(context, reason, msg)
+}
@@ -0,0 +1,67 @@
+package actor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPreStartRunsBeforeFirstMessage(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	actor := system.Spawn(func(msg Message, context *ActorContext) {
+		out <- msg[0]
+	}, WithLifecycleHooks(LifecycleHooks{
+		PreStart: func(context *ActorContext) {
+			out <- "preStart"
+		},
+	}))
+
+	actor.Send(Message{"hello"})
+	expect(t, out, "preStart")
+	expect(t, out, "hello")
+}
+
+func TestPostStopRunsAfterChildrenAndBeforeDown(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	var child *Actor
+	parent := system.Spawn(nop, WithLifecycleHooks(LifecycleHooks{
+		PreStart: func(context *ActorContext) {
+			child = context.Self.Spawn(nop)
+		},
+		PostStop: func(context *ActorContext) {
+			out <- child.State()
+		},
+	}))
+	watchDown(system, parent, out)
+
+	parent.Terminate()
+	expect(t, out, Stopped)
+	expect(t, out, "terminated")
+}
+
+func TestPanicInPreStartFailsActor(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	parent := spawnSupervisor(system, NewOneForOneStrategy(func(reason interface{}) Directive {
+		out <- reason
+		return Stop
+	}))
+	child := parent.Spawn(func(msg Message, context *ActorContext) {
+		out <- msg[0]
+	}, WithLifecycleHooks(LifecycleHooks{
+		PreStart: func(context *ActorContext) {
+			panic("preStart")
+		},
+	}))
+
+	child.Send(Message{"hello"})
+	expect(t, out, "preStart")
+	eventually(t, func() bool {
+		return child.State() == Stopped
+	})
+	expectNothing(t, out, 10*time.Millisecond)
+}
@@ -0,0 +1,273 @@
+package actor
+
+import (
+	"errors"
+	"sync"
+)
+
+// OverflowPolicy decides what happens when a message is sent to a full mailbox.
+type OverflowPolicy int
+
+const (
+	// BlockSender blocks the sender until the mailbox has room.
+	// Please note that an actor sending to itself with this policy may block forever.
+	BlockSender OverflowPolicy = iota
+	// DropNewest discards the message being sent.
+	DropNewest
+	// DropOldest discards the oldest message in the mailbox to make room, and publishes
+	// it to system's DeadLetters.  PoisonPill queued by Terminate is never discarded.
+	DropOldest
+	// DropToDeadLetters publishes the message being sent to system's DeadLetters.
+	DropToDeadLetters
+	// FailSender makes Send return ErrMailboxFull.
+	FailSender
+)
+
+// ErrMailboxFull is returned by Send when the mailbox with FailSender policy is full.
+var ErrMailboxFull = errors.New("actor: mailbox is full")
+
+// internal errors which make messages dead letters.
+var (
+	errMailboxClosed   = errors.New("actor: mailbox is closed")
+	errMailboxOverflow = errors.New("actor: mailbox overflowed")
+)
+
+// mailbox queues messages sent to an actor.
+//
+// enqueue can be called from any goroutine while dequeue is called only from
+// the actor's goroutine.  ready receives a signal when messages may be available.
+type mailbox interface {
+	// enqueue returns messages discarded to make room for env.
+	enqueue(env envelope) ([]envelope, error)
+	// enqueueSystem enqueues regardless of capacity (e.g. PoisonPill).
+	enqueueSystem(env envelope) error
+	// prepend puts messages back to the head of the mailbox regardless of capacity.
+	prepend(envs []envelope) error
+	dequeue() (envelope, bool)
+	ready() <-chan struct{}
+	// len returns the number of queued messages.
+	len() int
+	// close makes further enqueue fail and returns remained messages.
+	close() []envelope
+}
+
+// envelopeQueue is a queue of envelopes used by queueMailbox.
+// It is not safe for concurrent use.
+type envelopeQueue interface {
+	push(env envelope)
+	pushFront(env envelope)
+	pop() (envelope, bool)
+	len() int
+}
+
+// linkedQueue is a FIFO linked list of envelopes.
+type linkedQueue struct {
+	head *linkedNode
+	tail *linkedNode
+	size int
+}
+
+type linkedNode struct {
+	env  envelope
+	next *linkedNode
+}
+
+func (q *linkedQueue) push(env envelope) {
+	node := &linkedNode{env: env}
+	if q.tail == nil {
+		q.head = node
+	} else {
+		q.tail.next = node
+	}
+	q.tail = node
+	q.size++
+}
+
+func (q *linkedQueue) pushFront(env envelope) {
+	node := &linkedNode{env: env, next: q.head}
+	q.head = node
+	if q.tail == nil {
+		q.tail = node
+	}
+	q.size++
+}
+
+func (q *linkedQueue) pop() (envelope, bool) {
+	node := q.head
+	if node == nil {
+		return envelope{}, false
+	}
+	q.head = node.next
+	if q.head == nil {
+		q.tail = nil
+	}
+	q.size--
+	return node.env, true
+}
+
+func (q *linkedQueue) len() int {
+	return q.size
+}
+
+func drain(q envelopeQueue) []envelope {
+	envs := make([]envelope, 0, q.len())
+	for env, ok := q.pop(); ok; env, ok = q.pop() {
+		envs = append(envs, env)
+	}
+	return envs
+}
+
+// queueMailbox is a mailbox backed by envelopeQueue.
+//
+// Senders enqueue synchronously under the lock, so messages from a given sender
+// are always dequeued in the order they were sent (unless the queue reorders them
+// by priority).  Zero capacity means the mailbox is unbounded, otherwise the
+// overflow policy is applied when it is full.
+type queueMailbox struct {
+	lock      sync.Mutex
+	notFull   *sync.Cond
+	queue     envelopeQueue
+	capacity  int
+	policy    OverflowPolicy
+	closed    bool
+	readyChan chan struct{}
+}
+
+func newUnboundedMailbox() *queueMailbox {
+	return newQueueMailbox(&linkedQueue{}, 0, BlockSender)
+}
+
+func newBoundedMailbox(capacity int, policy OverflowPolicy) *queueMailbox {
+	return newQueueMailbox(&linkedQueue{}, capacity, policy)
+}
+
+func newQueueMailbox(queue envelopeQueue, capacity int, policy OverflowPolicy) *queueMailbox {
+	mb := &queueMailbox{
+		queue:     queue,
+		capacity:  capacity,
+		policy:    policy,
+		readyChan: make(chan struct{}, 1),
+	}
+	mb.notFull = sync.NewCond(&mb.lock)
+	return mb
+}
+
+func (mb *queueMailbox) enqueue(env envelope) ([]envelope, error) {
+	mb.lock.Lock()
+	defer mb.lock.Unlock()
+	var dropped []envelope
+	for !mb.closed && mb.capacity > 0 && mb.queue.len() >= mb.capacity {
+		switch mb.policy {
+		case BlockSender:
+			mb.notFull.Wait()
+			continue
+		case DropNewest:
+			return nil, nil
+		case DropOldest:
+			oldest, ok := mb.dropOldest()
+			if !ok {
+				// only system messages are queued.
+				return dropped, errMailboxOverflow
+			}
+			dropped = append(dropped, oldest)
+		case DropToDeadLetters:
+			return nil, errMailboxOverflow
+		default: // FailSender
+			return nil, ErrMailboxFull
+		}
+	}
+	return dropped, mb.push(env)
+}
+
+// dropOldest removes the oldest message except PoisonPill, which must not be lost.
+// It must be called with lock held.
+func (mb *queueMailbox) dropOldest() (envelope, bool) {
+	var system []envelope
+	defer func() {
+		for i := len(system) - 1; i >= 0; i-- {
+			mb.queue.pushFront(system[i])
+		}
+	}()
+	for {
+		env, ok := mb.queue.pop()
+		if !ok {
+			return envelope{}, false
+		}
+		if !isPoisonPill(env.message) {
+			return env, true
+		}
+		system = append(system, env)
+	}
+}
+
+func (mb *queueMailbox) enqueueSystem(env envelope) error {
+	mb.lock.Lock()
+	defer mb.lock.Unlock()
+	return mb.push(env)
+}
+
+func (mb *queueMailbox) prepend(envs []envelope) error {
+	mb.lock.Lock()
+	defer mb.lock.Unlock()
+	if mb.closed {
+		return errMailboxClosed
+	}
+	for i := len(envs) - 1; i >= 0; i-- {
+		mb.queue.pushFront(envs[i])
+	}
+	if len(envs) > 0 {
+		mb.signal()
+	}
+	return nil
+}
+
+// push must be called with lock held.
+func (mb *queueMailbox) push(env envelope) error {
+	if mb.closed {
+		return errMailboxClosed
+	}
+	mb.queue.push(env)
+	mb.signal()
+	return nil
+}
+
+func (mb *queueMailbox) dequeue() (envelope, bool) {
+	mb.lock.Lock()
+	defer mb.lock.Unlock()
+	env, ok := mb.queue.pop()
+	if !ok {
+		return envelope{}, false
+	}
+	if mb.queue.len() > 0 {
+		mb.signal()
+	}
+	mb.notFull.Signal()
+	return env, true
+}
+
+func (mb *queueMailbox) ready() <-chan struct{} {
+	return mb.readyChan
+}
+
+func (mb *queueMailbox) len() int {
+	mb.lock.Lock()
+	defer mb.lock.Unlock()
+	return mb.queue.len()
+}
+
+func (mb *queueMailbox) close() []envelope {
+	mb.lock.Lock()
+	defer mb.lock.Unlock()
+	mb.closed = true
+	// wake up blocked senders so that their messages become dead letters.
+	mb.notFull.Broadcast()
+	return drain(mb.queue)
+}
+
+// signal must be called with lock held.
+func (mb *queueMailbox) signal() {
+	select {
+	case mb.readyChan <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,157 @@
+package actor
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func enqueueAll(t *testing.T, mb mailbox, msgs ...interface{}) {
+	t.Helper()
+	for _, msg := range msgs {
+		if _, err := mb.enqueue(envelope{message: Message{msg}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func dequeueAll(mb mailbox) []interface{} {
+	var msgs []interface{}
+	for {
+		env, ok := mb.dequeue()
+		if !ok {
+			return msgs
+		}
+		msgs = append(msgs, env.message[0])
+	}
+}
+
+func expectMessages(t *testing.T, got []interface{}, want ...interface{}) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, but got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, but got %v", want, got)
+		}
+	}
+}
+
+func TestDropNewest(t *testing.T) {
+	mb := newBoundedMailbox(2, DropNewest)
+	enqueueAll(t, mb, 1, 2, 3)
+	expectMessages(t, dequeueAll(mb), 1, 2)
+}
+
+func TestDropOldest(t *testing.T) {
+	mb := newBoundedMailbox(2, DropOldest)
+	enqueueAll(t, mb, 1, 2)
+	dropped, err := mb.enqueue(envelope{message: Message{3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dropped) != 1 || dropped[0].message[0] != 1 {
+		t.Fatalf("expected 1 to be dropped, but got %v", dropped)
+	}
+	expectMessages(t, dequeueAll(mb), 2, 3)
+}
+
+func TestDropOldestKeepsPoisonPill(t *testing.T) {
+	mb := newBoundedMailbox(2, DropOldest)
+	enqueueAll(t, mb, 1)
+	mb.enqueueSystem(envelope{message: Message{PoisonPill{}}})
+	enqueueAll(t, mb, 2, 3)
+	expectMessages(t, dequeueAll(mb), PoisonPill{}, 3)
+
+	// a mailbox filled with system messages drops the message being sent.
+	mb = newBoundedMailbox(1, DropOldest)
+	mb.enqueueSystem(envelope{message: Message{PoisonPill{}}})
+	if _, err := mb.enqueue(envelope{message: Message{1}}); err != errMailboxOverflow {
+		t.Fatalf("expected errMailboxOverflow, but got %v", err)
+	}
+	expectMessages(t, dequeueAll(mb), PoisonPill{})
+}
+
+func TestDropOldestKeepsPoisonPillInPriorityMailbox(t *testing.T) {
+	mb := newQueueMailbox(newPriorityQueue(func(msg Message) int { return 0 }, PoisonPillHighest), 2, DropOldest)
+	enqueueAll(t, mb, 1, 2)
+	mb.enqueueSystem(envelope{message: Message{PoisonPill{}}})
+	enqueueAll(t, mb, 3)
+	expectMessages(t, dequeueAll(mb), PoisonPill{}, 3)
+}
+
+func TestFailSenderAndDropToDeadLetters(t *testing.T) {
+	mb := newBoundedMailbox(1, FailSender)
+	enqueueAll(t, mb, 1)
+	if _, err := mb.enqueue(envelope{message: Message{2}}); err != ErrMailboxFull {
+		t.Fatalf("expected ErrMailboxFull, but got %v", err)
+	}
+	mb = newBoundedMailbox(1, DropToDeadLetters)
+	enqueueAll(t, mb, 1)
+	if _, err := mb.enqueue(envelope{message: Message{2}}); err != errMailboxOverflow {
+		t.Fatalf("expected errMailboxOverflow, but got %v", err)
+	}
+}
+
+func TestDroppedOldestBecomesDeadLetter(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.Shutdown()
+	out := make(chan interface{}, 10)
+	system.DeadLetters.Add(system.Spawn(func(msg Message, context *ActorContext) {
+		if dl, ok := msg[0].(DeadLetter); ok {
+			out <- dl.Message[0]
+		}
+	}))
+	block := make(chan struct{})
+	defer close(block)
+	started := make(chan interface{}, 10)
+	target := system.Spawn(func(msg Message, context *ActorContext) {
+		started <- msg[0]
+		<-block
+	}, WithMailbox(1, DropOldest))
+
+	target.Send(Message{"first"})
+	expect(t, started, "first")
+	target.Send(Message{"old"})
+	target.Send(Message{"new"})
+	expect(t, out, "old")
+}
+
+func TestOrderPerSender(t *testing.T) {
+	const senders, messages = 8, 1000
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 1)
+	last := make(map[int]int)
+	received, failed := 0, false
+	target := system.Spawn(func(msg Message, context *ActorContext) {
+		sender, seq := msg[0].(int), msg[1].(int)
+		if failed {
+			return
+		}
+		if prev, ok := last[sender]; ok && seq != prev+1 {
+			failed = true
+			out <- fmt.Sprintf("sender %d: %d came after %d", sender, seq, prev)
+			return
+		}
+		last[sender] = seq
+		received++
+		if received == senders*messages {
+			out <- "done"
+		}
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func(sender int) {
+			defer wg.Done()
+			for seq := 0; seq < messages; seq++ {
+				target.Send(Message{sender, seq})
+			}
+		}(i)
+	}
+	wg.Wait()
+	expect(t, out, "done")
+}
@@ -0,0 +1,25 @@
+package actor
+
+// Middleware wraps actor's behavior to intercept messages it receives.
+//
+// Middleware applies to the current behavior, so it keeps working after Become and restarts.
+// For example, a middleware logging every message would be:
+//   logging := func(next actor.Receive) actor.Receive {
+//     return func(msg actor.Message, context *actor.ActorContext) {
+//       log.Println(context.Self.Name, msg)
+//       next(msg, context)
+//     }
+//   }
+//   system.SpawnProps(actor.PropsFromReceive(receive).WithMiddleware(logging))
+type Middleware func(next Receive) Receive
+
+// chainMiddleware builds a Receive which calls the current behavior through middleware.
+func chainMiddleware(middleware []Middleware) Receive {
+	receive := Receive(func(msg Message, context *ActorContext) {
+		context.currentBehavior(msg, context)
+	})
+	for i := len(middleware) - 1; i >= 0; i-- {
+		receive = middleware[i](receive)
+	}
+	return receive
+}
@@ -0,0 +1,99 @@
+package actor
+
+import "time"
+
+// SpawnOption configures an actor being spawned.
+//
+// For example,
+//   system.SpawnWithName("logger", receive, actor.WithMailbox(1000, actor.DropOldest))
+type SpawnOption func(*spawnOptions)
+
+type spawnOptions struct {
+	newMailbox         func() mailbox
+	stashCapacity      int
+	hooks              LifecycleHooks
+	supervisorStrategy *SupervisorStrategy
+	dispatcher         Dispatcher
+	middleware         []Middleware
+	receiveTimeout     time.Duration
+	stopTimeout        time.Duration
+}
+
+func newSpawnOptions(options []SpawnOption) *spawnOptions {
+	o := &spawnOptions{
+		newMailbox: func() mailbox {
+			return newUnboundedMailbox()
+		},
+		dispatcher:  DefaultDispatcher,
+		stopTimeout: DefaultStopTimeout,
+	}
+	for _, option := range options {
+		option(o)
+	}
+	return o
+}
+
+// WithMailbox spawns an actor with a mailbox of a given capacity and overflow policy.
+//
+// Without this option, an actor is spawned with an unbounded mailbox which
+// never drops messages nor blocks senders.
+func WithMailbox(capacity int, policy OverflowPolicy) SpawnOption {
+	return func(o *spawnOptions) {
+		o.newMailbox = func() mailbox {
+			return newBoundedMailbox(capacity, policy)
+		}
+	}
+}
+
+// WithUnboundedMailbox spawns an actor with an unbounded mailbox.
+//
+// This is the default.  Send to the mailbox never blocks nor drops messages,
+// and messages from a given sender are received in the order they were sent.
+func WithUnboundedMailbox() SpawnOption {
+	return func(o *spawnOptions) {
+		o.newMailbox = func() mailbox {
+			return newUnboundedMailbox()
+		}
+	}
+}
+
+// WithSupervisorStrategy spawns an actor with a strategy to supervise its children.
+//
+// It is the same as calling context.SetSupervisorStrategy at the beginning of the actor.
+func WithSupervisorStrategy(strategy *SupervisorStrategy) SpawnOption {
+	return func(o *spawnOptions) {
+		o.supervisorStrategy = strategy
+	}
+}
+
+// WithDispatcher spawns an actor whose loop is run by a given dispatcher.
+//
+// Without this option, DefaultDispatcher is used.
+func WithDispatcher(dispatcher Dispatcher) SpawnOption {
+	return func(o *spawnOptions) {
+		o.dispatcher = dispatcher
+	}
+}
+
+// WithMiddleware spawns an actor whose behavior is wrapped by given middleware.
+//
+// The first middleware is the outermost one.
+func WithMiddleware(middleware ...Middleware) SpawnOption {
+	return func(o *spawnOptions) {
+		o.middleware = append(o.middleware, middleware...)
+	}
+}
+
+// DefaultStopTimeout is how long a stopping actor waits for its children to stop by default.
+const DefaultStopTimeout = 5 * time.Second
+
+// WithStopTimeout spawns an actor which waits for its children to stop for a given duration when it stops.
+//
+// A stopping actor stops its children and waits for them before it calls PostStop and
+// notifies Down to its monitors.  When the timeout elapsed, it stops without waiting
+// for remaining children.
+func WithStopTimeout(d time.Duration) SpawnOption {
+	return func(o *spawnOptions) {
+		o.stopTimeout = d
+	}
+}
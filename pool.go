@@ -0,0 +1,230 @@
+package actor
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// defaultResizeInterval is how often a resizer checks the pool when Interval is not specified.
+const defaultResizeInterval = time.Second
+
+// replacements of workers are limited to defaultReplacementsPerWorker times the pool size
+// within defaultReplacementWindow when the pool's supervisor strategy has no restart limit.
+const (
+	defaultReplacementsPerWorker = 10
+	defaultReplacementWindow     = time.Second
+)
+
+// Resizer grows and shrinks a pool based on mailbox pressure of its workers.
+//
+// Every Interval (1 second if not positive), a worker is regarded as busy when its
+// mailbox has at least PressureThreshold (1 if not positive) messages.  The pool
+// grows by a worker when all the workers are busy and shrinks by a worker when
+// none of them is busy, within MinSize (at least 1) and MaxSize.
+type Resizer struct {
+	MinSize           int
+	MaxSize           int
+	PressureThreshold int
+	Interval          time.Duration
+}
+
+// PoolOption configures a pool spawned by SpawnPool.
+type PoolOption func(*pool)
+
+// WithResizer spawns a pool whose size is adjusted by a given resizer.
+//
+// The initial size is clamped to MinSize and MaxSize of the resizer.
+func WithResizer(resizer Resizer) PoolOption {
+	return func(p *pool) {
+		p.resizer = &resizer
+	}
+}
+
+// WithPoolSupervisorStrategy spawns a pool which supervises its workers with a given strategy.
+//
+// Without this option, crashed workers are restarted (see DefaultSupervisorStrategy).
+// The restart limit of the strategy also limits replacements of stopped workers.
+func WithPoolSupervisorStrategy(strategy *SupervisorStrategy) PoolOption {
+	return func(p *pool) {
+		p.supervisorStrategy = strategy
+	}
+}
+
+// internal message used in pool
+type poolResize struct{}
+
+type pool struct {
+	router             *ForwardingActor
+	props              Props
+	size               int
+	resizer            *Resizer
+	supervisorStrategy *SupervisorStrategy
+	timer              *time.Timer
+	replacements       restartStats
+}
+
+// SpawnPool creates and starts a top level router with n workers spawned from props as its children.
+//
+// Messages are routed to the workers by a given strategy.  Crashed workers are
+// supervised by the router, and stopped workers are replaced by new ones so that
+// the pool keeps its size.  When workers are replaced more often than the restart
+// limit of the pool's supervisor strategy (10 times per worker in a second if it has
+// no limit), the pool stops and its monitors receive Down with "restart-limit" Cause.
+// The name in props is ignored and workers are named sequentially.  It panics if
+// the name is invalid or already used by another top level actor.
+// For example,
+//   pool := system.SpawnPool("workers", 4, actor.PropsFromReceive(work), actor.RoundRobin,
+//     actor.WithResizer(actor.Resizer{MinSize: 2, MaxSize: 16}))
+//   pool.Send(Message{"job"})
+func (system *ActorSystem) SpawnPool(name string, n int, props Props, strategy RoutingStrategy, options ...PoolOption) *ForwardingActor {
+	if err := validateActorName(name); err != nil {
+		panic(err)
+	}
+	return newPool(n, props, options).start(system.newTopLevelActor, name, strategy)
+}
+
+// SpawnPool creates and starts a child router with n workers spawned from props as its children.
+//
+// Please see ActorSystem.SpawnPool for details.
+func (actor *Actor) SpawnPool(name string, n int, props Props, strategy RoutingStrategy, options ...PoolOption) *ForwardingActor {
+	if err := validateActorName(name); err != nil {
+		panic(err)
+	}
+	return newPool(n, props, options).start(actor.newChildActor, name, strategy)
+}
+
+func newPool(n int, props Props, options []PoolOption) *pool {
+	p := &pool{props: props.WithName(""), size: n}
+	for _, option := range options {
+		option(p)
+	}
+	if r := p.resizer; r != nil {
+		if r.MinSize < 1 {
+			r.MinSize = 1
+		}
+		if r.MaxSize < r.MinSize {
+			r.MaxSize = r.MinSize
+		}
+		if r.PressureThreshold <= 0 {
+			r.PressureThreshold = 1
+		}
+		if r.Interval <= 0 {
+			r.Interval = defaultResizeInterval
+		}
+		if p.size < r.MinSize {
+			p.size = r.MinSize
+		}
+		if p.size > r.MaxSize {
+			p.size = r.MaxSize
+		}
+	}
+	return p
+}
+
+func (p *pool) start(newActor func(props Props) (*Actor, error), name string, strategy RoutingStrategy) *ForwardingActor {
+	p.router = newForwardingActor(strategy, nil)
+	props := PropsFromReceive(p.receive()).WithName(name).WithLifecycleHooks(LifecycleHooks{
+		PreStart: p.preStart,
+		PostStop: p.postStop,
+	})
+	if p.supervisorStrategy != nil {
+		props = props.WithSupervisorStrategy(p.supervisorStrategy)
+	}
+	return p.router.start(newActor, props)
+}
+
+func (p *pool) preStart(context *ActorContext) {
+	for i := 0; i < p.size; i++ {
+		p.spawnWorker(context)
+	}
+	p.scheduleResize(context.Self)
+}
+
+func (p *pool) postStop(context *ActorContext) {
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+}
+
+func (p *pool) spawnWorker(context *ActorContext) {
+	worker := mustActor(context.Self.newChildActor(p.props))
+	// watch before the worker starts so that the pool never misses its Down.
+	worker.context.processAttachMonitor(context.Self)
+	p.router.add(spawn(worker))
+}
+
+func (p *pool) receive() Receive {
+	route := p.router.receive()
+	return func(msg Message, context *ActorContext) {
+		if len(msg) == 1 {
+			switch m := msg[0].(type) {
+			case Down:
+				if m.Actor.parent == context.Self {
+					// the worker stopped.  replace it unless the pool has shrunk.
+					p.router.remove(m.Actor)
+					if len(p.router.recipients) < p.size && !p.permitReplacement(context) {
+						fmt.Fprintf(os.Stderr, "[%s] workers stopped too often: stopping the pool\n", context.Self.Name)
+						self := context.Self
+						go func() {
+							defer logPanic(self)
+							self.context.killWith("restart-limit")
+						}()
+						return
+					}
+					for len(p.router.recipients) < p.size {
+						p.spawnWorker(context)
+					}
+					return
+				}
+			case poolResize:
+				p.resize(context)
+				p.scheduleResize(context.Self)
+				return
+			}
+		}
+		route(msg, context)
+	}
+}
+
+// permitReplacement records a replacement and returns false when the pool exceeded the limit.
+func (p *pool) permitReplacement(context *ActorContext) bool {
+	strategy := context.strategy()
+	if strategy == nil || strategy.MaxNrOfRetries <= 0 {
+		strategy = &SupervisorStrategy{
+			MaxNrOfRetries:  defaultReplacementsPerWorker * p.size,
+			WithinTimeRange: defaultReplacementWindow,
+		}
+	}
+	return strategy.requestRestartPermission(&p.replacements, time.Now())
+}
+
+func (p *pool) scheduleResize(self *Actor) {
+	if p.resizer == nil {
+		return
+	}
+	p.timer = time.AfterFunc(p.resizer.Interval, func() {
+		self.Send(Message{poolResize{}})
+	})
+}
+
+func (p *pool) resize(context *ActorContext) {
+	workers := p.router.recipients
+	busy := 0
+	for _, worker := range workers {
+		if worker.context.mailbox.len() >= p.resizer.PressureThreshold {
+			busy++
+		}
+	}
+	switch {
+	case busy == len(workers) && p.size < p.resizer.MaxSize:
+		p.size++
+		p.spawnWorker(context)
+	case busy == 0 && p.size > p.resizer.MinSize && len(workers) > 0:
+		p.size--
+		worker := workers[len(workers)-1]
+		p.router.remove(worker)
+		// the worker finishes queued messages before it stops.
+		worker.Terminate()
+	}
+}
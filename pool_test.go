@@ -0,0 +1,127 @@
+package actor
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// poolWorker reports its name and quits on "quit".
+func poolWorker(out chan interface{}) Receive {
+	return func(msg Message, context *ActorContext) {
+		if msg[0] == "quit" {
+			context.Self.Terminate()
+		}
+		out <- context.Self.Name
+	}
+}
+
+// workersOf waits until the pool spawned n workers and returns them.
+func workersOf(t *testing.T, pool *ForwardingActor, n int) []*Actor {
+	t.Helper()
+	eventually(t, func() bool {
+		return len(pool.Recipients()) == n
+	})
+	return pool.Recipients()
+}
+
+func TestPoolRoutesToWorkers(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	pool := system.SpawnPool("pool", 3, PropsFromReceive(poolWorker(out)).WithName("ignored"), RoundRobin)
+
+	workers := map[interface{}]bool{}
+	for i := 0; i < 6; i++ {
+		pool.Send(Message{i})
+		workers[<-out] = true
+	}
+	if len(workers) != 3 {
+		t.Fatalf("messages must be routed to 3 workers: %v", workers)
+	}
+}
+
+func TestPoolReplacesStoppedWorkers(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	pool := system.SpawnPool("pool", 2, PropsFromReceive(poolWorker(out)), RoundRobin)
+	quitting := workersOf(t, pool, 2)[0]
+
+	pool.Send(Message{"quit"})
+	<-out
+	eventually(t, func() bool {
+		recipients := pool.Recipients()
+		return len(recipients) == 2 && recipients[0] != quitting && recipients[1] != quitting
+	})
+}
+
+// stopsOnStart spawns workers which stop as soon as they start.
+var stopsOnStart = PropsFromReceive(nop).WithLifecycleHooks(LifecycleHooks{
+	PreStart: func(context *ActorContext) {
+		context.Self.Terminate()
+	},
+})
+
+func TestPoolStopsWhenWorkersExceedRestartLimit(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	pool := system.SpawnPool("pool", 2, stopsOnStart, RoundRobin,
+		WithPoolSupervisorStrategy(DefaultSupervisorStrategy.WithRestartLimit(3, time.Minute)))
+	watchDown(system, pool.Actor, out)
+
+	expect(t, out, "restart-limit")
+}
+
+func TestPoolLimitsReplacementsByDefault(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	pool := system.SpawnPool("pool", 2, stopsOnStart, RoundRobin)
+	watchDown(system, pool.Actor, out)
+
+	expect(t, out, "restart-limit")
+}
+
+func TestPoolRestartsCrashedWorkers(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	pool := system.SpawnPool("pool", 1, PropsFromReceive(func(msg Message, context *ActorContext) {
+		if msg[0] == "boom" {
+			panic("boom")
+		}
+		out <- context.Self
+	}), RoundRobin)
+	worker := workersOf(t, pool, 1)[0]
+
+	pool.Send(Message{"boom"})
+	pool.Send(Message{"hello"})
+	// restarted in place, not replaced.
+	expect(t, out, worker)
+}
+
+func TestPoolResizer(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	release := make(chan struct{})
+	var releaseOnce sync.Once
+	releaseAll := func() {
+		releaseOnce.Do(func() {
+			close(release)
+		})
+	}
+	defer releaseAll()
+	pool := system.SpawnPool("pool", 1, PropsFromReceive(func(msg Message, context *ActorContext) {
+		<-release
+	}), SmallestMailbox, WithResizer(Resizer{MinSize: 1, MaxSize: 3, Interval: 10 * time.Millisecond}))
+
+	// keep every worker busy until the pool grows to its max size.
+	eventually(t, func() bool {
+		pool.Send(Message{"job"})
+		return len(pool.Recipients()) == 3
+	})
+	releaseAll()
+	workersOf(t, pool, 1)
+}
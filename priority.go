@@ -0,0 +1,123 @@
+package actor
+
+import "container/heap"
+
+// PoisonPillPriority decides when PoisonPill is processed in a priority mailbox.
+type PoisonPillPriority int
+
+const (
+	// PoisonPillLowest processes PoisonPill after all the other messages in the mailbox.
+	// The actor drains its mailbox before it stops.
+	PoisonPillLowest PoisonPillPriority = iota
+	// PoisonPillHighest processes PoisonPill before all the other messages in the mailbox.
+	// The actor stops as soon as possible and remained messages become dead letters.
+	PoisonPillHighest
+)
+
+// WithPriorityMailbox spawns an actor with an unbounded priority mailbox.
+//
+// Messages with higher priority are processed first.  Messages with equal
+// priority are processed in the order they were enqueued.
+// For example,
+//   system.Spawn(receive, actor.WithPriorityMailbox(func(msg Message) int {
+//     if msg[0] == "health-check" {
+//       return 1
+//     }
+//     return 0
+//   }, actor.PoisonPillLowest))
+func WithPriorityMailbox(priority func(msg Message) int, poisonPill PoisonPillPriority) SpawnOption {
+	return func(o *spawnOptions) {
+		o.newMailbox = func() mailbox {
+			return newQueueMailbox(newPriorityQueue(priority, poisonPill), 0, BlockSender)
+		}
+	}
+}
+
+const (
+	maxPriority = int(^uint(0) >> 1)
+	minPriority = -maxPriority - 1
+)
+
+// priorityQueue is a heap of envelopes ordered by priority and then by sequence.
+//
+// seq increases for pushed envelopes and decreases for envelopes pushed to front,
+// so that the latter come before the others with equal priority.
+type priorityQueue struct {
+	items      priorityItems
+	priority   func(msg Message) int
+	poisonPill PoisonPillPriority
+	seq        int64
+	frontSeq   int64
+}
+
+type priorityItem struct {
+	env      envelope
+	priority int
+	seq      int64
+}
+
+func newPriorityQueue(priority func(msg Message) int, poisonPill PoisonPillPriority) *priorityQueue {
+	return &priorityQueue{priority: priority, poisonPill: poisonPill}
+}
+
+func (q *priorityQueue) push(env envelope) {
+	q.seq++
+	heap.Push(&q.items, priorityItem{env: env, priority: q.priorityOf(env.message), seq: q.seq})
+}
+
+func (q *priorityQueue) pushFront(env envelope) {
+	q.frontSeq--
+	heap.Push(&q.items, priorityItem{env: env, priority: q.priorityOf(env.message), seq: q.frontSeq})
+}
+
+func (q *priorityQueue) pop() (envelope, bool) {
+	if len(q.items) == 0 {
+		return envelope{}, false
+	}
+	return heap.Pop(&q.items).(priorityItem).env, true
+}
+
+func (q *priorityQueue) len() int {
+	return len(q.items)
+}
+
+func (q *priorityQueue) priorityOf(msg Message) int {
+	if isPoisonPill(msg) {
+		if q.poisonPill == PoisonPillHighest {
+			return maxPriority
+		}
+		return minPriority
+	}
+	return q.priority(msg)
+}
+
+// priorityItems implements heap.Interface.
+type priorityItems []priorityItem
+
+func (items priorityItems) Len() int {
+	return len(items)
+}
+
+func (items priorityItems) Less(i, j int) bool {
+	if items[i].priority != items[j].priority {
+		return items[i].priority > items[j].priority
+	}
+	return items[i].seq < items[j].seq
+}
+
+func (items priorityItems) Swap(i, j int) {
+	items[i], items[j] = items[j], items[i]
+}
+
+func (items *priorityItems) Push(x interface{}) {
+	*items = append(*items, x.(priorityItem))
+}
+
+func (items *priorityItems) Pop() interface{} {
+	old := *items
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = priorityItem{}
+	*items = old[:n-1]
+	return item
+}
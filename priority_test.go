@@ -0,0 +1,95 @@
+package actor
+
+import (
+	"strings"
+	"testing"
+)
+
+// urgentFirst gives messages starting with "urgent" higher priority.
+func urgentFirst(msg Message) int {
+	if s, ok := msg[0].(string); ok && strings.HasPrefix(s, "urgent") {
+		return 1
+	}
+	return 0
+}
+
+func TestPriorityMailboxIsStable(t *testing.T) {
+	mb := newQueueMailbox(newPriorityQueue(urgentFirst, PoisonPillLowest), 0, BlockSender)
+	enqueueAll(t, mb, "normal1", "urgent1", "normal2", "urgent2", "normal3", "urgent3")
+	expectMessages(t, dequeueAll(mb), "urgent1", "urgent2", "urgent3", "normal1", "normal2", "normal3")
+}
+
+func TestPriorityMailboxPrepend(t *testing.T) {
+	mb := newQueueMailbox(newPriorityQueue(urgentFirst, PoisonPillLowest), 0, BlockSender)
+	enqueueAll(t, mb, "normal3", "urgent2")
+	mb.prepend([]envelope{{message: Message{"normal1"}}, {message: Message{"normal2"}}, {message: Message{"urgent1"}}})
+	expectMessages(t, dequeueAll(mb), "urgent1", "urgent2", "normal1", "normal2", "normal3")
+}
+
+func TestPoisonPillPriority(t *testing.T) {
+	mb := newQueueMailbox(newPriorityQueue(urgentFirst, PoisonPillLowest), 0, BlockSender)
+	enqueueAll(t, mb, "normal")
+	mb.enqueueSystem(envelope{message: Message{PoisonPill{}}})
+	enqueueAll(t, mb, "urgent", "normal")
+	expectMessages(t, dequeueAll(mb), "urgent", "normal", "normal", PoisonPill{})
+
+	mb = newQueueMailbox(newPriorityQueue(urgentFirst, PoisonPillHighest), 0, BlockSender)
+	enqueueAll(t, mb, "normal", "urgent")
+	mb.enqueueSystem(envelope{message: Message{PoisonPill{}}})
+	expectMessages(t, dequeueAll(mb), PoisonPill{}, "urgent", "normal")
+}
+
+// spawnBlockedPriorityActor spawns an actor which blocks on its first message until
+// release is closed, so that following messages stay in its mailbox.  Its Down is
+// reported to out.
+func spawnBlockedPriorityActor(t *testing.T, system *ActorSystem, poisonPill PoisonPillPriority, out chan interface{}, release chan struct{}) *Actor {
+	started := make(chan interface{}, 1)
+	actor := system.Spawn(func(msg Message, context *ActorContext) {
+		if msg[0] == "block" {
+			started <- "block"
+			<-release
+			return
+		}
+		out <- msg[0]
+	}, WithPriorityMailbox(urgentFirst, poisonPill))
+	watchDown(system, actor, out)
+	actor.Send(Message{"block"})
+	expect(t, started, "block")
+	return actor
+}
+
+func TestPoisonPillLowestDrainsMailbox(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	release := make(chan struct{})
+	actor := spawnBlockedPriorityActor(t, system, PoisonPillLowest, out, release)
+
+	actor.Send(Message{"normal"})
+	actor.context.terminate()
+	actor.Send(Message{"urgent"})
+	close(release)
+	expect(t, out, "urgent")
+	expect(t, out, "normal")
+	expect(t, out, "terminated")
+}
+
+func TestPoisonPillHighestStopsFirst(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	deadLetters := make(chan interface{}, 10)
+	system.DeadLetters.Add(system.Spawn(func(msg Message, context *ActorContext) {
+		if dl, ok := msg[0].(DeadLetter); ok {
+			deadLetters <- dl.Message[0]
+		}
+	}))
+	release := make(chan struct{})
+	actor := spawnBlockedPriorityActor(t, system, PoisonPillHighest, out, release)
+
+	actor.Send(Message{"urgent"})
+	actor.context.terminate()
+	close(release)
+	expect(t, out, "terminated")
+	expect(t, deadLetters, "urgent")
+}
@@ -0,0 +1,140 @@
+package actor
+
+import "time"
+
+// Receiver is an interface for struct-based actors.
+//
+// A struct-based actor can keep its state in its fields instead of captured
+// variables.  It can also implement PreStarter, PostStopper, PreRestarter and
+// PostRestarter to hook its lifecycle.
+// For example,
+//   type counter struct {
+//     count int
+//   }
+//   func (c *counter) Receive(context *actor.ActorContext, msg actor.Message) {
+//     c.count++
+//   }
+//   system.SpawnProps(actor.NewProps(func() actor.Receiver { return &counter{} }))
+type Receiver interface {
+	Receive(context *ActorContext, msg Message)
+}
+
+// Receive adapts a message handler function to Receiver.
+func (receive Receive) Receive(context *ActorContext, msg Message) {
+	receive(msg, context)
+}
+
+// Props describes how to create an actor.
+//
+// Props carries a producer of Receiver which is called when the actor starts and
+// every time the actor restarts, so that each incarnation gets a fresh instance.
+// It also carries the actor's name and spawn options.  Props is immutable: With*
+// methods return a modified copy so that a Props can be shared and spawned many times.
+// For example,
+//   props := actor.PropsFromReceive(receive).
+//     WithName("worker").
+//     WithMailbox(100, actor.BlockSender).
+//     WithSupervisorStrategy(actor.NewOneForOneStrategy(decider))
+//   system.SpawnProps(props)
+type Props struct {
+	producer func() Receiver
+	name     string
+	options  []SpawnOption
+}
+
+// NewProps creates Props from a producer of Receiver.
+func NewProps(producer func() Receiver) Props {
+	return Props{producer: producer}
+}
+
+// PropsFromReceive creates Props from a message handler function.
+//
+// Please note that restarted actor shares the function (and variables captured by it)
+// with its previous incarnation.
+func PropsFromReceive(receive Receive) Props {
+	return NewProps(func() Receiver {
+		return receive
+	})
+}
+
+// newReceiver produces a Receiver and its behavior.
+func (props Props) newReceiver() (Receiver, Receive) {
+	receiver := props.producer()
+	if receive, ok := receiver.(Receive); ok {
+		return receiver, receive
+	}
+	return receiver, func(msg Message, context *ActorContext) {
+		receiver.Receive(context, msg)
+	}
+}
+
+// WithName returns a copy of the props with a given actor name.
+//
+// Without name, the actor is named by a sequence number unique among its siblings.
+func (props Props) WithName(name string) Props {
+	props.name = name
+	return props
+}
+
+// WithOptions returns a copy of the props with given spawn options appended.
+//
+// Options are applied in order, so a later option overrides former ones.
+func (props Props) WithOptions(options ...SpawnOption) Props {
+	if len(options) == 0 {
+		return props
+	}
+	merged := make([]SpawnOption, 0, len(props.options)+len(options))
+	merged = append(merged, props.options...)
+	props.options = append(merged, options...)
+	return props
+}
+
+// WithMailbox returns a copy of the props with a bounded mailbox (see WithMailbox option).
+func (props Props) WithMailbox(capacity int, policy OverflowPolicy) Props {
+	return props.WithOptions(WithMailbox(capacity, policy))
+}
+
+// WithUnboundedMailbox returns a copy of the props with an unbounded mailbox (see WithUnboundedMailbox option).
+func (props Props) WithUnboundedMailbox() Props {
+	return props.WithOptions(WithUnboundedMailbox())
+}
+
+// WithPriorityMailbox returns a copy of the props with a priority mailbox (see WithPriorityMailbox option).
+func (props Props) WithPriorityMailbox(priority func(msg Message) int, poisonPill PoisonPillPriority) Props {
+	return props.WithOptions(WithPriorityMailbox(priority, poisonPill))
+}
+
+// WithStashCapacity returns a copy of the props with a stash capacity (see WithStashCapacity option).
+func (props Props) WithStashCapacity(capacity int) Props {
+	return props.WithOptions(WithStashCapacity(capacity))
+}
+
+// WithLifecycleHooks returns a copy of the props with lifecycle hooks (see WithLifecycleHooks option).
+func (props Props) WithLifecycleHooks(hooks LifecycleHooks) Props {
+	return props.WithOptions(WithLifecycleHooks(hooks))
+}
+
+// WithSupervisorStrategy returns a copy of the props with a supervisor strategy (see WithSupervisorStrategy option).
+func (props Props) WithSupervisorStrategy(strategy *SupervisorStrategy) Props {
+	return props.WithOptions(WithSupervisorStrategy(strategy))
+}
+
+// WithDispatcher returns a copy of the props with a dispatcher (see WithDispatcher option).
+func (props Props) WithDispatcher(dispatcher Dispatcher) Props {
+	return props.WithOptions(WithDispatcher(dispatcher))
+}
+
+// WithMiddleware returns a copy of the props with middleware appended (see WithMiddleware option).
+func (props Props) WithMiddleware(middleware ...Middleware) Props {
+	return props.WithOptions(WithMiddleware(middleware...))
+}
+
+// WithReceiveTimeout returns a copy of the props with a receive timeout (see WithReceiveTimeout option).
+func (props Props) WithReceiveTimeout(d time.Duration) Props {
+	return props.WithOptions(WithReceiveTimeout(d))
+}
+
+// WithStopTimeout returns a copy of the props with a stop timeout (see WithStopTimeout option).
+func (props Props) WithStopTimeout(d time.Duration) Props {
+	return props.WithOptions(WithStopTimeout(d))
+}
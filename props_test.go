@@ -0,0 +1,162 @@
+package actor
+
+import (
+	"testing"
+	"time"
+)
+
+// counter is a struct-based actor which reports its incarnation and count.
+type counter struct {
+	incarnation int
+	count       int
+	out         chan interface{}
+}
+
+func (c *counter) Receive(context *ActorContext, msg Message) {
+	switch msg[0] {
+	case "boom":
+		panic("boom")
+	case "count":
+		c.count++
+		c.out <- c.count
+	}
+}
+
+func (c *counter) PreRestart(context *ActorContext, reason interface{}, msg Message) {
+	c.out <- "preRestart"
+	c.out <- c.incarnation
+}
+
+func (c *counter) PostRestart(context *ActorContext, reason interface{}) {
+	c.out <- "postRestart"
+	c.out <- c.incarnation
+}
+
+func TestRestartProducesFreshReceiver(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	incarnations := 0
+	actor := system.SpawnProps(NewProps(func() Receiver {
+		incarnations++
+		return &counter{incarnation: incarnations, out: out}
+	}))
+
+	actor.Send(Message{"count"})
+	actor.Send(Message{"count"})
+	expect(t, out, 1)
+	expect(t, out, 2)
+	actor.Send(Message{"boom"})
+	actor.Send(Message{"count"})
+	// hooks are called on the failed instance and then on the fresh one.
+	expect(t, out, "preRestart")
+	expect(t, out, 1)
+	expect(t, out, "postRestart")
+	expect(t, out, 2)
+	expect(t, out, 1)
+}
+
+// recordingDispatcher reports its name to out whenever it dispatches a loop.
+type recordingDispatcher struct {
+	name string
+	out  chan interface{}
+}
+
+func (d recordingDispatcher) Dispatch(loop func()) {
+	d.out <- d.name
+	go loop()
+}
+
+// tagging is a middleware which reports tag to out before every message.
+func tagging(tag string, out chan interface{}) Middleware {
+	return func(next Receive) Receive {
+		return func(msg Message, context *ActorContext) {
+			out <- tag
+			next(msg, context)
+		}
+	}
+}
+
+func TestPropsWithDispatcher(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	actor := system.SpawnProps(PropsFromReceive(func(msg Message, context *ActorContext) {
+		out <- msg[0]
+	}).WithDispatcher(recordingDispatcher{"dispatcher", out}))
+
+	expect(t, out, "dispatcher")
+	actor.Send(Message{"hello"})
+	expect(t, out, "hello")
+}
+
+func TestPropsWithMiddlewareKeepsWorkingAfterBecome(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	actor := system.SpawnProps(PropsFromReceive(becoming(out)).WithMiddleware(tagging("outer", out), tagging("inner", out)))
+
+	actor.Send(Message{"who"})
+	expect(t, out, "outer")
+	expect(t, out, "inner")
+	expect(t, out, "A")
+	actor.Send(Message{"become"})
+	actor.Send(Message{"who"})
+	expect(t, out, "outer")
+	expect(t, out, "inner")
+	expect(t, out, "outer")
+	expect(t, out, "inner")
+	expect(t, out, "B")
+}
+
+func TestPropsWithOptionsCopies(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	base := PropsFromReceive(nop).WithStashCapacity(1)
+	first := base.WithDispatcher(recordingDispatcher{"first", out})
+	second := base.WithDispatcher(recordingDispatcher{"second", out})
+
+	system.SpawnProps(first)
+	expect(t, out, "first")
+	system.SpawnProps(second)
+	expect(t, out, "second")
+	system.SpawnProps(base)
+	expectNothing(t, out, 10*time.Millisecond)
+}
+
+func TestPinnedDispatcher(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	actor := system.SpawnProps(PropsFromReceive(func(msg Message, context *ActorContext) {
+		out <- msg[0]
+	}).WithDispatcher(PinnedDispatcher))
+	watchDown(system, actor, out)
+
+	actor.Send(Message{"hello"})
+	expect(t, out, "hello")
+	actor.Terminate()
+	expect(t, out, "terminated")
+}
+
+func TestRouterWithOptions(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	routee := system.Spawn(func(msg Message, context *ActorContext) {
+		out <- msg[0]
+	})
+	router := system.SpawnRouterWithOptions("router", RoundRobin, []*Actor{routee},
+		WithDispatcher(recordingDispatcher{"router", out}), WithMiddleware(tagging("routed", out)))
+	forwarder := system.SpawnForwardActorWithOptions("forwarder", []*Actor{routee},
+		WithDispatcher(recordingDispatcher{"forwarder", out}))
+
+	expect(t, out, "router")
+	expect(t, out, "forwarder")
+	router.Send(Message{"hello"})
+	expect(t, out, "routed")
+	expect(t, out, "hello")
+	forwarder.Send(Message{"world"})
+	expect(t, out, "world")
+}
@@ -0,0 +1,68 @@
+package actor
+
+import "sync"
+
+// ActorState is a lifecycle state of an actor.
+type ActorState int
+
+const (
+	// Starting is the state of an actor which is spawned but not started its loop yet.
+	Starting ActorState = iota
+	// Running is the state of an actor which processes messages.
+	Running
+	// Stopping is the state of an actor which left its loop and is cleaning up (e.g. PostStop).
+	Stopping
+	// Stopped is the state of an actor which finished.
+	Stopped
+)
+
+func (state ActorState) String() string {
+	switch state {
+	case Starting:
+		return "Starting"
+	case Running:
+		return "Running"
+	case Stopping:
+		return "Stopping"
+	case Stopped:
+		return "Stopped"
+	}
+	return "Unknown"
+}
+
+// registry keeps lifecycle states of actors in an actor system.
+//
+// It is safe for concurrent use.  Stopped actors are removed from the registry
+// so that it doesn't grow, and unknown actors are regarded as Stopped.
+type registry struct {
+	lock   sync.RWMutex
+	states map[*Actor]ActorState
+}
+
+func newRegistry() *registry {
+	return &registry{states: make(map[*Actor]ActorState)}
+}
+
+// register registers a newly created actor as Starting.
+func (r *registry) register(actor *Actor) {
+	r.transition(actor, Starting)
+}
+
+func (r *registry) transition(actor *Actor, state ActorState) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if state == Stopped {
+		delete(r.states, actor)
+		return
+	}
+	r.states[actor] = state
+}
+
+func (r *registry) state(actor *Actor) ActorState {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if state, ok := r.states[actor]; ok {
+		return state
+	}
+	return Stopped
+}
@@ -0,0 +1,120 @@
+package actor
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestActorStates(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	actor := mustActor(system.newTopLevelActor(PropsFromReceive(func(msg Message, context *ActorContext) {
+		out <- context.Self.State()
+	})))
+	if actor.State() != Starting || actor.IsRunning() {
+		t.Fatalf("spawned actor must be Starting, but %s", actor.State())
+	}
+	spawn(actor)
+	actor.Send(Message{"state"})
+	expect(t, out, Running)
+	if !actor.IsRunning() {
+		t.Fatal("IsRunning must be true while the actor is Running")
+	}
+
+	watchDown(system, actor, out)
+	actor.Terminate()
+	expect(t, out, "terminated")
+	// Down is sent while the actor is Stopping.
+	eventually(t, func() bool {
+		return actor.State() == Stopped
+	})
+}
+
+func TestRegistryUnderConcurrentSpawnAndStop(t *testing.T) {
+	system := NewActorSystem("test")
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				actor := system.Spawn(func(msg Message, context *ActorContext) {
+					for k := 0; k < 3; k++ {
+						context.Self.Spawn(nop)
+					}
+					for _, child := range context.Children() {
+						child.State()
+					}
+				})
+				actor.Send(Message{"spawn"})
+				actor.IsRunning()
+				system.TopLevelActors()
+				if i%2 == 0 {
+					actor.Terminate()
+				} else {
+					actor.Kill()
+				}
+				actor.State()
+			}
+		}()
+	}
+	// observers read states while actors come and go.
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			for _, actor := range system.TopLevelActors() {
+				actor.IsRunning()
+			}
+		}
+	}()
+	wg.Wait()
+	close(done)
+	system.GracefulShutdown()
+
+	system.registry.lock.RLock()
+	defer system.registry.lock.RUnlock()
+	if n := len(system.registry.states); n != 0 {
+		t.Fatalf("%d actors remain in the registry after shutdown", n)
+	}
+}
+
+func TestShutdownSkipsStoppingActors(t *testing.T) {
+	system := NewActorSystem("test")
+	out := make(chan interface{}, 10)
+	release := make(chan struct{})
+	stopping := system.Spawn(nop, WithLifecycleHooks(LifecycleHooks{
+		PostStop: func(context *ActorContext) {
+			<-release
+		},
+	}))
+	system.Spawn(nop, WithLifecycleHooks(LifecycleHooks{
+		PostStop: func(context *ActorContext) {
+			out <- "stopped"
+		},
+	}))
+
+	stopping.Terminate()
+	eventually(t, func() bool {
+		return stopping.State() == Stopping
+	})
+	shutdown := make(chan struct{})
+	go func() {
+		system.Shutdown()
+		close(shutdown)
+	}()
+	// the running actor is killed without waiting for the stopping one.
+	expect(t, out, "stopped")
+	close(release)
+	select {
+	case <-shutdown:
+	case <-time.After(testTimeout):
+		t.Fatal("timed out waiting for shutdown")
+	}
+}
@@ -0,0 +1,126 @@
+package actor
+
+import "math/rand"
+
+// RoutingStrategy decides to which routees a router forwards each message.
+//
+// Available strategies are RoundRobin, Random, Broadcast, SmallestMailbox and ConsistentHashing.
+type RoutingStrategy interface {
+	// newRoutingLogic is called for each router so that routers don't share their state.
+	newRoutingLogic() routingLogic
+}
+
+var (
+	// RoundRobin forwards messages to routees in turn.
+	RoundRobin RoutingStrategy = routingStrategyFunc(func() routingLogic { return &roundRobinLogic{} })
+	// Random forwards each message to a randomly chosen routee.
+	Random RoutingStrategy = routingStrategyFunc(func() routingLogic { return randomLogic{} })
+	// Broadcast forwards each message to all the routees.
+	Broadcast RoutingStrategy = routingStrategyFunc(func() routingLogic { return broadcastLogic{} })
+	// SmallestMailbox forwards each message to the routee with the fewest queued messages.
+	SmallestMailbox RoutingStrategy = routingStrategyFunc(func() routingLogic { return smallestMailboxLogic{} })
+)
+
+// SpawnRouter creates and starts a top level router which forwards messages to routees by a given strategy.
+//
+// Routees can be added and removed by Add and Remove of the returned router.
+// The sender of a message is kept when it is forwarded.
+// It panics if the name is invalid or already used by another top level actor.
+// For example,
+//   router := system.SpawnRouter("workers", actor.RoundRobin, worker1, worker2)
+//   router.Add(worker3)
+//   router.Send(Message{"job"}) // ==> worker1 receives it.
+func (system *ActorSystem) SpawnRouter(name string, strategy RoutingStrategy, routees ...*Actor) *ForwardingActor {
+	return system.SpawnRouterWithOptions(name, strategy, routees)
+}
+
+// SpawnRouterWithOptions is the same as SpawnRouter except that it takes spawn options.
+//
+// For example,
+//   router := system.SpawnRouterWithOptions("workers", actor.RoundRobin, []*Actor{worker1, worker2},
+//     actor.WithDispatcher(actor.PinnedDispatcher))
+func (system *ActorSystem) SpawnRouterWithOptions(name string, strategy RoutingStrategy, routees []*Actor, options ...SpawnOption) *ForwardingActor {
+	if err := validateActorName(name); err != nil {
+		panic(err)
+	}
+	return spawnRouter(system.newTopLevelActor, name, strategy, routees, options...)
+}
+
+// SpawnRouter creates and starts a child router which forwards messages to routees by a given strategy.
+//
+// Please see ActorSystem.SpawnRouter for details.
+func (actor *Actor) SpawnRouter(name string, strategy RoutingStrategy, routees ...*Actor) *ForwardingActor {
+	return actor.SpawnRouterWithOptions(name, strategy, routees)
+}
+
+// SpawnRouterWithOptions is the same as SpawnRouter except that it takes spawn options.
+func (actor *Actor) SpawnRouterWithOptions(name string, strategy RoutingStrategy, routees []*Actor, options ...SpawnOption) *ForwardingActor {
+	if err := validateActorName(name); err != nil {
+		panic(err)
+	}
+	return spawnRouter(actor.newChildActor, name, strategy, routees, options...)
+}
+
+// routingLogic is the state of a strategy for a router.  It is used only in the router's goroutine.
+type routingLogic interface {
+	route(msg Message, routees []*Actor) []*Actor
+}
+
+// recipientsObserver is implemented by routing logic which keeps state derived from routees.
+type recipientsObserver interface {
+	recipientsChanged(routees []*Actor)
+}
+
+type routingStrategyFunc func() routingLogic
+
+func (f routingStrategyFunc) newRoutingLogic() routingLogic {
+	return f()
+}
+
+type roundRobinLogic struct {
+	next int
+}
+
+func (logic *roundRobinLogic) route(msg Message, routees []*Actor) []*Actor {
+	if len(routees) == 0 {
+		return nil
+	}
+	routee := routees[logic.next%len(routees)]
+	logic.next = (logic.next + 1) % len(routees)
+	return []*Actor{routee}
+}
+
+type randomLogic struct{}
+
+func (randomLogic) route(msg Message, routees []*Actor) []*Actor {
+	if len(routees) == 0 {
+		return nil
+	}
+	return []*Actor{routees[rand.Intn(len(routees))]}
+}
+
+type broadcastLogic struct{}
+
+func (broadcastLogic) route(msg Message, routees []*Actor) []*Actor {
+	return routees
+}
+
+type smallestMailboxLogic struct{}
+
+func (smallestMailboxLogic) route(msg Message, routees []*Actor) []*Actor {
+	var smallest *Actor
+	min := 0
+	for _, routee := range routees {
+		n := routee.context.mailbox.len()
+		if smallest == nil || n < min {
+			smallest, min = routee, n
+		}
+		if n == 0 {
+			break
+		}
+	}
+	if smallest == nil {
+		return nil
+	}
+	return []*Actor{smallest}
+}
@@ -0,0 +1,149 @@
+package actor
+
+import (
+	"testing"
+)
+
+func spawnRoutees(system *ActorSystem, n int) []*Actor {
+	routees := make([]*Actor, n)
+	for i := range routees {
+		routees[i] = system.Spawn(nop)
+	}
+	return routees
+}
+
+func TestRoundRobin(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	routees := spawnRoutees(system, 3)
+	logic := RoundRobin.newRoutingLogic()
+	for i := 0; i < 7; i++ {
+		routed := logic.route(Message{i}, routees)
+		if len(routed) != 1 || routed[0] != routees[i%3] {
+			t.Fatalf("message %d must be routed to routee %d", i, i%3)
+		}
+	}
+	// fewer routees after removal.
+	if routed := logic.route(Message{"x"}, routees[:1]); len(routed) != 1 || routed[0] != routees[0] {
+		t.Fatal("round robin must wrap around the current routees")
+	}
+	if routed := logic.route(Message{"x"}, nil); len(routed) != 0 {
+		t.Fatal("nothing is routed without routees")
+	}
+}
+
+func TestRandom(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	routees := spawnRoutees(system, 3)
+	logic := Random.newRoutingLogic()
+	counts := map[*Actor]int{}
+	for i := 0; i < 300; i++ {
+		routed := logic.route(Message{i}, routees)
+		if len(routed) != 1 {
+			t.Fatalf("a message must be routed to one routee: %v", routed)
+		}
+		counts[routed[0]]++
+	}
+	for i, routee := range routees {
+		if counts[routee] == 0 {
+			t.Fatalf("routee %d received nothing", i)
+		}
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	routees := spawnRoutees(system, 3)
+	expectActors(t, Broadcast.newRoutingLogic().route(Message{"x"}, routees), routees...)
+}
+
+func TestSmallestMailbox(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	started := make(chan interface{}, 2)
+	release := make(chan struct{})
+	defer close(release)
+	busy := func(msg Message, context *ActorContext) {
+		if msg[0] == "block" {
+			started <- context.Self
+			<-release
+		}
+	}
+	routees := []*Actor{system.Spawn(busy), system.Spawn(busy), system.Spawn(busy)}
+	for i, routee := range routees[:2] {
+		routee.Send(Message{"block"})
+		<-started
+		for j := 0; j < 2-i; j++ {
+			routee.Send(Message{j})
+		}
+	}
+	// queued: 2, 1 and 0 messages.
+	logic := SmallestMailbox.newRoutingLogic()
+	if routed := logic.route(Message{"x"}, routees); len(routed) != 1 || routed[0] != routees[2] {
+		t.Fatal("the routee with the empty mailbox must be chosen")
+	}
+	if routed := logic.route(Message{"x"}, routees[:2]); len(routed) != 1 || routed[0] != routees[1] {
+		t.Fatal("the routee with the fewest messages must be chosen")
+	}
+}
+
+func TestRouterForwardsWithSender(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	report := func(msg Message, context *ActorContext) {
+		out <- context.Self
+		out <- context.Sender()
+	}
+	first := system.Spawn(report)
+	second := system.Spawn(report)
+	sender := system.Spawn(nop)
+	router := system.SpawnRouter("router", RoundRobin, first, second)
+
+	router.SendFrom(Message{"a"}, sender)
+	expect(t, out, first)
+	expect(t, out, sender)
+	router.SendFrom(Message{"b"}, sender)
+	expect(t, out, second)
+	expect(t, out, sender)
+}
+
+func TestRouterWithoutRouteesPublishesDeadLetters(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	deadLetters := make(chan interface{}, 10)
+	system.DeadLetters.Add(system.Spawn(func(msg Message, context *ActorContext) {
+		if dl, ok := msg[0].(DeadLetter); ok {
+			deadLetters <- dl.Message[0]
+		}
+	}))
+	router := system.SpawnRouter("router", RoundRobin)
+
+	router.Send(Message{"lost"})
+	expect(t, deadLetters, "lost")
+}
+
+func TestAddingStoppedRouteeDropsIt(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	stopped := system.Spawn(nop)
+	stopped.Terminate()
+	eventually(t, func() bool {
+		return stopped.State() == Stopped
+	})
+	router := system.SpawnRouter("router", RoundRobin)
+	router.context.attachMonitor(system.Spawn(func(msg Message, context *ActorContext) {
+		if _, ok := msg[0].(RecipientsEmpty); ok {
+			out <- "empty"
+		}
+	}))
+
+	router.Add(stopped)
+	expect(t, out, "empty")
+	if n := len(router.Recipients()); n != 0 {
+		t.Fatalf("%d routees remain", n)
+	}
+}
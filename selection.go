@@ -0,0 +1,133 @@
+package actor
+
+import (
+	"path"
+	"strings"
+)
+
+// ActorSelection is a set of actors selected by a path pattern.
+//
+// The pattern is resolved every time the selection is used, so that actors
+// spawned after the selection was made are also selected.
+type ActorSelection struct {
+	system  *ActorSystem
+	anchor  *Actor
+	pattern string
+}
+
+// ActorOf returns a running actor at a given path, or nil if there is no such actor.
+//
+// The path is an absolute actor path (e.g. "/foo/bar") or a canonical name (e.g. "systemX:/foo/bar").
+// Please see ActorPath and CanonicalName.
+func (system *ActorSystem) ActorOf(path string) *Actor {
+	return system.actorOf(system.guardian, path)
+}
+
+// Select returns a selection of running actors matching a given path pattern.
+//
+// Each path segment can contain wildcards of path.Match (e.g. '*' and '?').
+// For example,
+//   system.Select("/workers/*").Send(Message{"reload"})
+func (system *ActorSystem) Select(pattern string) *ActorSelection {
+	return &ActorSelection{system: system, anchor: system.guardian, pattern: pattern}
+}
+
+// ActorOf returns a running actor at a given path, or nil if there is no such actor.
+//
+// The path can be relative to the actor.  ".." means its parent.
+// For example,
+//   sibling := context.ActorOf("../sibling")
+//   grandChild := context.ActorOf("child/grandChild")
+func (context *ActorContext) ActorOf(path string) *Actor {
+	return context.Self.System.actorOf(context.Self, path)
+}
+
+// Select returns a selection of running actors matching a given path pattern.
+//
+// The pattern can be relative to the actor as well as ActorOf, and each path segment
+// can contain wildcards as well as ActorSystem.Select.
+// For example,
+//   context.Select("../*").Send(Message{"hello, siblings"})
+func (context *ActorContext) Select(pattern string) *ActorSelection {
+	return &ActorSelection{system: context.Self.System, anchor: context.Self, pattern: pattern}
+}
+
+// Actors returns a snapshot of actors matching the selection.
+func (selection *ActorSelection) Actors() []*Actor {
+	return selection.system.resolve(selection.anchor, selection.pattern, true)
+}
+
+// Send sends message to all the actors matching the selection.
+func (selection *ActorSelection) Send(msg Message) {
+	selection.SendFrom(msg, nil)
+}
+
+// SendFrom sends message with its sender to all the actors matching the selection.
+func (selection *ActorSelection) SendFrom(msg Message, sender *Actor) {
+	for _, actor := range selection.Actors() {
+		actor.SendFrom(msg, sender)
+	}
+}
+
+func (system *ActorSystem) actorOf(anchor *Actor, path string) *Actor {
+	actors := system.resolve(anchor, path, false)
+	if len(actors) == 0 {
+		return nil
+	}
+	return actors[0]
+}
+
+// resolve walks actor hierarchy from anchor along path and returns running actors at the end.
+// Path segments are matched as wildcards only when wildcard is true.
+func (system *ActorSystem) resolve(anchor *Actor, p string, wildcard bool) []*Actor {
+	p = strings.TrimPrefix(p, system.Name+":")
+	if strings.HasPrefix(p, "/") {
+		anchor = system.guardian
+	}
+	current := []*Actor{anchor}
+	for _, segment := range strings.Split(p, "/") {
+		if segment == "" || segment == "." {
+			continue
+		}
+		next := []*Actor{}
+		found := map[*Actor]bool{}
+		add := func(actor *Actor) {
+			if actor != nil && !found[actor] {
+				found[actor] = true
+				next = append(next, actor)
+			}
+		}
+		for _, actor := range current {
+			if segment == ".." {
+				add(actor.parent)
+				continue
+			}
+			children := system.childrenOf(actor)
+			if !wildcard {
+				add(children.Get(segment))
+				continue
+			}
+			for _, child := range children.snapshot() {
+				if matched, err := path.Match(segment, child.Name); err == nil && matched {
+					add(child)
+				}
+			}
+		}
+		current = next
+	}
+	actors := []*Actor{}
+	for _, actor := range current {
+		if actor != system.guardian && actor.IsRunning() {
+			actors = append(actors, actor)
+		}
+	}
+	return actors
+}
+
+// childrenOf returns top level actors for the guardian so that internal actors can't be looked up.
+func (system *ActorSystem) childrenOf(actor *Actor) *actorSet {
+	if actor == system.guardian {
+		return system.topLevelActors
+	}
+	return actor.children
+}
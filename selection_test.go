@@ -0,0 +1,132 @@
+package actor
+
+import (
+	"testing"
+	"time"
+)
+
+func expectActors(t *testing.T, got []*Actor, want ...*Actor) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected %d actors, but got %d", len(want), len(got))
+	}
+	found := map[*Actor]bool{}
+	for _, actor := range got {
+		found[actor] = true
+	}
+	for _, actor := range want {
+		if !found[actor] {
+			t.Fatalf("%s is not selected", actor.ActorPath())
+		}
+	}
+}
+
+// lookup answers Message{"actorOf", path} and Message{"select", pattern} from its context.
+func lookup(out chan interface{}) Receive {
+	return func(msg Message, context *ActorContext) {
+		switch msg[0] {
+		case "actorOf":
+			out <- context.ActorOf(msg[1].(string))
+		case "select":
+			out <- context.Select(msg[1].(string)).Actors()
+		}
+	}
+}
+
+func TestActorOf(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	workers := system.SpawnWithName("workers", nop)
+	w1 := workers.SpawnWithName("w1", nop)
+
+	if system.ActorOf("/workers/w1") != w1 || system.ActorOf("test:/workers/w1") != w1 {
+		t.Fatal("absolute path must resolve to the actor")
+	}
+	if system.ActorOf("/workers/missing") != nil || system.ActorOf("/workers/w*") != nil {
+		t.Fatal("ActorOf must not match missing actors nor wildcards")
+	}
+	if system.ActorOf("/") != nil {
+		t.Fatal("the guardian must not be looked up")
+	}
+}
+
+func TestSelectWithWildcards(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	report := func(msg Message, context *ActorContext) {
+		out <- context.Self
+	}
+	workers := system.SpawnWithName("workers", nop)
+	w1 := workers.SpawnWithName("w1", report)
+	w2 := workers.SpawnWithName("w2", report)
+	other := workers.SpawnWithName("other", report)
+	backup := system.SpawnWithName("backup", nop)
+	w3 := backup.SpawnWithName("w3", report)
+
+	expectActors(t, system.Select("/workers/w*").Actors(), w1, w2)
+	expectActors(t, system.Select("/workers/*").Actors(), w1, w2, other)
+	expectActors(t, system.Select("/*/w?").Actors(), w1, w2, w3)
+	expectActors(t, system.Select("/workers/[a-z]*/none").Actors())
+
+	system.Select("/*/w?").Send(Message{"hello"})
+	received := []*Actor{}
+	for i := 0; i < 3; i++ {
+		select {
+		case actor := <-out:
+			received = append(received, actor.(*Actor))
+		case <-time.After(testTimeout):
+			t.Fatal("timed out")
+		}
+	}
+	expectActors(t, received, w1, w2, w3)
+}
+
+func TestSelectSkipsStoppedActors(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	workers := system.SpawnWithName("workers", nop)
+	w1 := workers.SpawnWithName("w1", nop)
+	w2 := workers.SpawnWithName("w2", nop)
+	watchDown(system, w2, out)
+
+	w2.Terminate()
+	expect(t, out, "terminated")
+	expectActors(t, system.Select("/workers/*").Actors(), w1)
+}
+
+func TestRelativeSelection(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	workers := system.SpawnWithName("workers", nop)
+	w1 := workers.SpawnWithName("w1", lookup(out))
+	w2 := workers.SpawnWithName("w2", nop)
+	sub := w1.SpawnWithName("sub", nop)
+	backup := system.SpawnWithName("backup", nop)
+
+	w1.Send(Message{"actorOf", "../w2"})
+	expect(t, out, w2)
+	w1.Send(Message{"actorOf", "sub"})
+	expect(t, out, sub)
+	w1.Send(Message{"actorOf", "./sub/../../w2"})
+	expect(t, out, w2)
+	w1.Send(Message{"actorOf", "/backup"})
+	expect(t, out, backup)
+
+	w1.Send(Message{"select", "../*"})
+	select {
+	case actors := <-out:
+		expectActors(t, actors.([]*Actor), w1, w2)
+	case <-time.After(testTimeout):
+		t.Fatal("timed out")
+	}
+	w1.Send(Message{"select", "../../*"})
+	select {
+	case actors := <-out:
+		expectActors(t, actors.([]*Actor), workers, backup)
+	case <-time.After(testTimeout):
+		t.Fatal("timed out")
+	}
+}
@@ -0,0 +1,57 @@
+package actor
+
+import "errors"
+
+// ErrStashFull is returned by Stash when the stash exceeds its capacity.
+var ErrStashFull = errors.New("actor: stash is full")
+
+// WithStashCapacity limits the number of messages which the actor can stash.
+//
+// Without this option, the stash is unbounded.
+func WithStashCapacity(capacity int) SpawnOption {
+	return func(o *spawnOptions) {
+		o.stashCapacity = capacity
+	}
+}
+
+// Stash sets aside the message being processed.
+//
+// Stashed messages can be put back to the mailbox by UnstashAll.  This is useful
+// when messages arrive in the state which can't process them.  Stashed messages
+// which remain when the actor stops are published to system's DeadLetters.
+// For example,
+//   waiting := func(msg Message, context *ActorContext){
+//     if msg[0] == "open" {
+//       context.UnstashAll()
+//       context.Unbecome()
+//     } else {
+//       context.Stash()
+//     }
+//   }
+func (context *ActorContext) Stash() error {
+	if context.current.message == nil {
+		return nil
+	}
+	if context.stashCapacity > 0 && len(context.stash) >= context.stashCapacity {
+		return ErrStashFull
+	}
+	context.stash = append(context.stash, context.current)
+	return nil
+}
+
+// UnstashAll puts all the stashed messages back to the head of the mailbox in original order.
+func (context *ActorContext) UnstashAll() {
+	if len(context.stash) == 0 {
+		return
+	}
+	if err := context.mailbox.prepend(context.stash); err != nil {
+		for _, env := range context.stash {
+			context.Self.System.publishDeadLetter(DeadLetter{
+				Message:   env.message,
+				Sender:    env.sender,
+				Recipient: context.Self,
+			})
+		}
+	}
+	context.stash = nil
+}
@@ -0,0 +1,78 @@
+package actor
+
+import (
+	"testing"
+)
+
+// stashUntilOpen stashes messages until it receives "open", then reports messages to out.
+func stashUntilOpen(out chan interface{}) Receive {
+	opened := func(msg Message, context *ActorContext) {
+		out <- msg[0]
+	}
+	return func(msg Message, context *ActorContext) {
+		if msg[0] == "open" {
+			context.UnstashAll()
+			context.Become(opened, false)
+			return
+		}
+		if err := context.Stash(); err != nil {
+			out <- err
+		}
+	}
+}
+
+func TestUnstashAllKeepsOrder(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	actor := system.Spawn(stashUntilOpen(out))
+
+	actor.Send(Message{1})
+	actor.Send(Message{2})
+	actor.Send(Message{3})
+	actor.Send(Message{"open"})
+	actor.Send(Message{4})
+	for i := 1; i <= 4; i++ {
+		expect(t, out, i)
+	}
+}
+
+func TestStashCapacity(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	actor := system.Spawn(stashUntilOpen(out), WithStashCapacity(2))
+
+	actor.Send(Message{1})
+	actor.Send(Message{2})
+	actor.Send(Message{3})
+	expect(t, out, ErrStashFull)
+	actor.Send(Message{"open"})
+	expect(t, out, 1)
+	expect(t, out, 2)
+}
+
+func TestStashedMessagesBecomeDeadLettersOnStop(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	deadLetters := make(chan interface{}, 10)
+	system.DeadLetters.Add(system.Spawn(func(msg Message, context *ActorContext) {
+		if dl, ok := msg[0].(DeadLetter); ok {
+			deadLetters <- dl.Message[0]
+			deadLetters <- dl.Sender
+		}
+	}))
+	sender := system.Spawn(nop)
+	actor := system.Spawn(stashUntilOpen(out))
+	watchDown(system, actor, out)
+
+	actor.SendFrom(Message{1}, sender)
+	actor.SendFrom(Message{2}, sender)
+	actor.Terminate()
+	expect(t, out, "terminated")
+	expect(t, deadLetters, 1)
+	expect(t, deadLetters, sender)
+	expect(t, deadLetters, 2)
+	expect(t, deadLetters, sender)
+}
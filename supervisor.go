@@ -0,0 +1,153 @@
+package actor
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Directive is a decision which a supervisor makes when its child failed.
+type Directive int
+
+const (
+	// Resume lets the failed child keep its state and process next message.
+	Resume Directive = iota
+	// Restart resets the failed child's behavior to its original behavior.
+	Restart
+	// Stop stops the failed child.
+	Stop
+	// Escalate fails the supervisor itself with the same reason.
+	Escalate
+)
+
+func (d Directive) String() string {
+	switch d {
+	case Resume:
+		return "Resume"
+	case Restart:
+		return "Restart"
+	case Stop:
+		return "Stop"
+	case Escalate:
+		return "Escalate"
+	}
+	return fmt.Sprintf("Directive(%d)", int(d))
+}
+
+// Decider decides a directive from the value recovered from child's panic.
+type Decider func(reason interface{}) Directive
+
+// SupervisorStrategy is applied by a parent actor when its child's Receive panics.
+//
+// One-for-one strategy applies the directive only to the failed child, while
+// all-for-one strategy applies it to all the children of the parent.
+// For example,
+//   strategy := actor.NewOneForOneStrategy(func(reason interface{}) actor.Directive {
+//     if reason == "fatal" {
+//       return actor.Stop
+//     }
+//     return actor.Resume
+//   })
+//
+// MaxNrOfRetries limits the number of restarts of a child within WithinTimeRange.
+// When the child exceeds the limit, it is stopped and its monitors receive
+// Down message whose Cause is "restart-limit".  Zero MaxNrOfRetries means no limit,
+// and zero WithinTimeRange means the window is infinite.
+type SupervisorStrategy struct {
+	Decider         Decider
+	AllForOne       bool
+	MaxNrOfRetries  int
+	WithinTimeRange time.Duration
+}
+
+// NewOneForOneStrategy creates a strategy which applies directives only to the failed child.
+func NewOneForOneStrategy(decider Decider) *SupervisorStrategy {
+	return &SupervisorStrategy{Decider: decider}
+}
+
+// NewAllForOneStrategy creates a strategy which applies directives to all the children.
+func NewAllForOneStrategy(decider Decider) *SupervisorStrategy {
+	return &SupervisorStrategy{Decider: decider, AllForOne: true}
+}
+
+// WithRestartLimit returns a copy of the strategy which stops a child restarted
+// more than maxNrOfRetries times within a given duration.
+func (strategy *SupervisorStrategy) WithRestartLimit(maxNrOfRetries int, within time.Duration) *SupervisorStrategy {
+	s := *strategy
+	s.MaxNrOfRetries = maxNrOfRetries
+	s.WithinTimeRange = within
+	return &s
+}
+
+// DefaultDecider restarts the failed child whatever the reason is.
+func DefaultDecider(reason interface{}) Directive {
+	return Restart
+}
+
+// DefaultSupervisorStrategy is used when no strategy was set to the parent.
+var DefaultSupervisorStrategy = NewOneForOneStrategy(DefaultDecider)
+
+// internal messages used in supervision
+type failure struct {
+	child   *Actor
+	reason  interface{}
+	message Message
+}
+type supervisorDirective struct {
+	directive Directive
+	cause     string
+	reason    interface{}
+}
+
+// restartStats records when a child was restarted.
+type restartStats struct {
+	restarts []time.Time
+}
+
+func (strategy *SupervisorStrategy) decide(reason interface{}) Directive {
+	if strategy == nil || strategy.Decider == nil {
+		return DefaultDecider(reason)
+	}
+	return strategy.Decider(reason)
+}
+
+// targets returns children to which the directive will be applied.
+func (strategy *SupervisorStrategy) targets(failed *Actor, children *actorSet) []*Actor {
+	if strategy == nil || !strategy.AllForOne {
+		return []*Actor{failed}
+	}
+	targets := []*Actor{failed}
+	children.Do(func(child *Actor) {
+		if child != failed && child.IsRunning() {
+			targets = append(targets, child)
+		}
+	})
+	return targets
+}
+
+// requestRestartPermission records a restart and returns false when the child
+// exceeded the restart limit.
+func (strategy *SupervisorStrategy) requestRestartPermission(stats *restartStats, now time.Time) bool {
+	if strategy == nil || strategy.MaxNrOfRetries <= 0 {
+		return true
+	}
+	if strategy.WithinTimeRange > 0 {
+		windowStart := now.Add(-strategy.WithinTimeRange)
+		recent := stats.restarts[:0]
+		for _, t := range stats.restarts {
+			if t.After(windowStart) {
+				recent = append(recent, t)
+			}
+		}
+		stats.restarts = recent
+	}
+	if len(stats.restarts) >= strategy.MaxNrOfRetries {
+		return false
+	}
+	stats.restarts = append(stats.restarts, now)
+	return true
+}
+
+func logFailure(actor *Actor, reason interface{}) {
+	fmt.Fprintf(os.Stderr, "[%s] failed: %v\n", actor.Name, reason)
+}
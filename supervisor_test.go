@@ -0,0 +1,259 @@
+package actor
+
+import (
+	"testing"
+	"time"
+)
+
+// becoming answers "who" with "A", or with "B" after "become".  It panics on "boom".
+func becoming(out chan interface{}) Receive {
+	var b Receive = func(msg Message, context *ActorContext) {
+		switch msg[0] {
+		case "boom":
+			panic("boom")
+		case "who":
+			out <- "B"
+		}
+	}
+	return func(msg Message, context *ActorContext) {
+		switch msg[0] {
+		case "boom":
+			panic("boom")
+		case "become":
+			context.Become(b, false)
+		case "who":
+			out <- "A"
+		}
+	}
+}
+
+func always(directive Directive) Decider {
+	return func(reason interface{}) Directive {
+		return directive
+	}
+}
+
+func spawnSupervisor(system *ActorSystem, strategy *SupervisorStrategy) *Actor {
+	return system.SpawnProps(PropsFromReceive(nop).WithSupervisorStrategy(strategy))
+}
+
+func TestOneForOneResumeKeepsBehavior(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	parent := spawnSupervisor(system, NewOneForOneStrategy(always(Resume)))
+	child := parent.Spawn(becoming(out))
+
+	child.Send(Message{"become"})
+	child.Send(Message{"boom"})
+	child.Send(Message{"who"})
+	expect(t, out, "B")
+}
+
+func TestOneForOneRestartResetsBehavior(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	parent := spawnSupervisor(system, NewOneForOneStrategy(always(Restart)))
+	child := parent.Spawn(becoming(out))
+	sibling := parent.Spawn(becoming(out))
+
+	sibling.Send(Message{"become"})
+	child.Send(Message{"become"})
+	child.Send(Message{"boom"})
+	child.Send(Message{"who"})
+	expect(t, out, "A")
+	// the sibling is not affected.
+	sibling.Send(Message{"who"})
+	expect(t, out, "B")
+}
+
+func TestOneForOneStop(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	parent := spawnSupervisor(system, NewOneForOneStrategy(always(Stop)))
+	child := parent.Spawn(becoming(out))
+	sibling := parent.Spawn(becoming(out))
+	watchDown(system, child, out)
+
+	child.Send(Message{"boom"})
+	expect(t, out, "stopped")
+	sibling.Send(Message{"who"})
+	expect(t, out, "A")
+}
+
+func TestEscalateFollowsGrandparentDecision(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	grandparent := spawnSupervisor(system, NewOneForOneStrategy(always(Restart)))
+	parent := grandparent.SpawnProps(PropsFromReceive(nop).WithSupervisorStrategy(NewOneForOneStrategy(always(Escalate))))
+	child := parent.Spawn(becoming(out))
+
+	child.Send(Message{"become"})
+	child.Send(Message{"boom"})
+	child.Send(Message{"who"})
+	expect(t, out, "A")
+}
+
+func TestEscalateStop(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	grandparent := spawnSupervisor(system, NewOneForOneStrategy(always(Stop)))
+	parent := grandparent.SpawnProps(PropsFromReceive(nop).WithSupervisorStrategy(NewOneForOneStrategy(always(Escalate))))
+	child := parent.Spawn(becoming(out))
+	watchDown(system, parent, out)
+
+	child.Send(Message{"boom"})
+	expect(t, out, "stopped")
+	if child.IsRunning() {
+		t.Fatal("child of the stopped parent must be stopped")
+	}
+}
+
+func TestAllForOneRestartsSiblings(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	parent := spawnSupervisor(system, NewAllForOneStrategy(always(Restart)))
+	child := parent.Spawn(becoming(out))
+	sibling := parent.Spawn(becoming(out))
+
+	sibling.Send(Message{"become"})
+	child.Send(Message{"become"})
+	child.Send(Message{"boom"})
+	child.Send(Message{"who"})
+	expect(t, out, "A")
+	// the directive reaches the sibling asynchronously.
+	eventually(t, func() bool {
+		sibling.Send(Message{"who"})
+		return <-out == "A"
+	})
+}
+
+func TestAllForOneStopsSiblings(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	parent := spawnSupervisor(system, NewAllForOneStrategy(always(Stop)))
+	child := parent.Spawn(becoming(out))
+	sibling := parent.Spawn(becoming(out))
+	downs := make(chan interface{}, 10)
+	watchDown(system, child, downs)
+	watchDown(system, sibling, downs)
+
+	child.Send(Message{"boom"})
+	expect(t, downs, "stopped")
+	expect(t, downs, "stopped")
+}
+
+func TestAllForOneResumeKeepsSiblings(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	parent := spawnSupervisor(system, NewAllForOneStrategy(always(Resume)))
+	child := parent.Spawn(becoming(out))
+	sibling := parent.Spawn(becoming(out))
+
+	sibling.Send(Message{"become"})
+	child.Send(Message{"become"})
+	child.Send(Message{"boom"})
+	child.Send(Message{"who"})
+	expect(t, out, "B")
+	sibling.Send(Message{"who"})
+	expect(t, out, "B")
+}
+
+func TestAllForOneEscalate(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	grandparent := spawnSupervisor(system, NewOneForOneStrategy(always(Stop)))
+	parent := grandparent.SpawnProps(PropsFromReceive(nop).WithSupervisorStrategy(NewAllForOneStrategy(always(Escalate))))
+	child := parent.Spawn(becoming(out))
+	parent.Spawn(becoming(out))
+	watchDown(system, parent, out)
+
+	child.Send(Message{"boom"})
+	expect(t, out, "stopped")
+}
+
+func TestGuardianStopsEscalatedFailure(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	system.SetSupervisorStrategy(NewOneForOneStrategy(always(Escalate)))
+	child := system.Spawn(becoming(out))
+	watchDown(system, child, out)
+
+	child.Send(Message{"boom"})
+	expect(t, out, "stopped")
+}
+
+func TestRestartLimitDeliversDown(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	parent := spawnSupervisor(system, DefaultSupervisorStrategy.WithRestartLimit(2, time.Minute))
+	child := parent.Spawn(becoming(out))
+	watchDown(system, child, out)
+
+	child.Send(Message{"boom"})
+	child.Send(Message{"boom"})
+	child.Send(Message{"who"})
+	expect(t, out, "A")
+	child.Send(Message{"boom"})
+	expect(t, out, "restart-limit")
+}
+
+func TestRestartPermissionWindow(t *testing.T) {
+	strategy := DefaultSupervisorStrategy.WithRestartLimit(2, time.Second)
+	stats := &restartStats{}
+	now := time.Now()
+	if !strategy.requestRestartPermission(stats, now) ||
+		!strategy.requestRestartPermission(stats, now.Add(100*time.Millisecond)) {
+		t.Fatal("restarts within the limit must be permitted")
+	}
+	if strategy.requestRestartPermission(stats, now.Add(200*time.Millisecond)) {
+		t.Fatal("the third restart within the window must be refused")
+	}
+	// the first restart left the window.
+	if !strategy.requestRestartPermission(stats, now.Add(1050*time.Millisecond)) {
+		t.Fatal("restart must be permitted after the window passed")
+	}
+
+	unlimited := DefaultSupervisorStrategy
+	for i := 0; i < 100; i++ {
+		if !unlimited.requestRestartPermission(stats, now) {
+			t.Fatal("strategy without limit must always permit restarts")
+		}
+	}
+}
+
+func TestRestartStartsWithFreshState(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	child := system.SpawnProps(NewProps(func() Receiver {
+		count := 0
+		return Receive(func(msg Message, context *ActorContext) {
+			switch msg[0] {
+			case "boom":
+				panic("boom")
+			case "count":
+				count++
+				out <- count
+			}
+		})
+	}))
+
+	child.Send(Message{"count"})
+	child.Send(Message{"count"})
+	expect(t, out, 1)
+	expect(t, out, 2)
+	child.Send(Message{"boom"})
+	child.Send(Message{"count"})
+	expect(t, out, 1)
+}
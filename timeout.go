@@ -0,0 +1,78 @@
+package actor
+
+import "time"
+
+// ReceiveTimeout is delivered to the actor when it has received no message for
+// the duration set by SetReceiveTimeout.
+type ReceiveTimeout struct{}
+
+// WithReceiveTimeout spawns an actor with a receive timeout (see SetReceiveTimeout).
+//
+// The receive timeout is set again when the actor restarts.
+func WithReceiveTimeout(d time.Duration) SpawnOption {
+	return func(o *spawnOptions) {
+		o.receiveTimeout = d
+	}
+}
+
+// SetReceiveTimeout delivers Message{ReceiveTimeout{}} to the current behavior
+// when no message has arrived for a given duration.
+//
+// The timeout is reset by each message, and ReceiveTimeout is delivered
+// repeatedly while the actor stays idle.  Zero duration cancels it.
+// For example, a session which expires after a minute of inactivity would be:
+//   session := func(msg Message, context *ActorContext){
+//     switch msg[0].(type) {
+//     case actor.ReceiveTimeout:
+//       context.Self.Terminate()
+//     default:
+//       ...
+//     }
+//   }
+//   system.SpawnProps(actor.PropsFromReceive(session).WithReceiveTimeout(time.Minute))
+func (context *ActorContext) SetReceiveTimeout(d time.Duration) {
+	context.receiveTimeout = d
+	context.resetReceiveTimeout()
+}
+
+// resetReceiveTimeout restarts the receive timeout timer.  It must be called in actor's goroutine.
+func (context *ActorContext) resetReceiveTimeout() {
+	if context.receiveTimeoutTimer != nil {
+		if !context.receiveTimeoutTimer.Stop() {
+			// drain the expired timer so that Reset doesn't deliver it twice.
+			select {
+			case <-context.receiveTimeoutTimer.C:
+			default:
+			}
+		}
+		if context.receiveTimeout <= 0 {
+			return
+		}
+		context.receiveTimeoutTimer.Reset(context.receiveTimeout)
+		return
+	}
+	if context.receiveTimeout > 0 {
+		context.receiveTimeoutTimer = time.NewTimer(context.receiveTimeout)
+	}
+}
+
+// stopReceiveTimeout stops the timer when the actor stops.
+func (context *ActorContext) stopReceiveTimeout() {
+	if context.receiveTimeoutTimer != nil {
+		context.receiveTimeoutTimer.Stop()
+	}
+}
+
+// receiveTimeoutChan returns nil while receive timeout is disabled or the actor
+// is suspended so that select ignores it.
+func (context *ActorContext) receiveTimeoutChan() <-chan time.Time {
+	if context.receiveTimeout <= 0 || context.suspended || context.receiveTimeoutTimer == nil {
+		return nil
+	}
+	return context.receiveTimeoutTimer.C
+}
+
+func (context *ActorContext) processReceiveTimeout() {
+	context.invoke(envelope{message: Message{ReceiveTimeout{}}})
+	context.resetReceiveTimeout()
+}
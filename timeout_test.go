@@ -0,0 +1,64 @@
+package actor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReceiveTimeoutIsResetByMessages(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	actor := system.Spawn(func(msg Message, context *ActorContext) {
+		if _, ok := msg[0].(ReceiveTimeout); ok {
+			out <- "timeout"
+		}
+	}, WithReceiveTimeout(200*time.Millisecond))
+
+	for i := 0; i < 10; i++ {
+		actor.Send(Message{"tick"})
+		time.Sleep(40 * time.Millisecond)
+	}
+	select {
+	case <-out:
+		t.Fatal("ReceiveTimeout was delivered while messages kept arriving")
+	default:
+	}
+	// delivered repeatedly while the actor stays idle.
+	expect(t, out, "timeout")
+	expect(t, out, "timeout")
+}
+
+func TestSetReceiveTimeoutZeroCancels(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	system.Spawn(func(msg Message, context *ActorContext) {
+		if _, ok := msg[0].(ReceiveTimeout); ok {
+			context.SetReceiveTimeout(0)
+			out <- "timeout"
+		}
+	}, WithReceiveTimeout(50*time.Millisecond))
+
+	expect(t, out, "timeout")
+	expectNothing(t, out, 200*time.Millisecond)
+}
+
+func TestSetReceiveTimeoutFromBehavior(t *testing.T) {
+	system := NewActorSystem("test")
+	defer system.GracefulShutdown()
+	out := make(chan interface{}, 10)
+	actor := system.Spawn(func(msg Message, context *ActorContext) {
+		switch msg[0].(type) {
+		case ReceiveTimeout:
+			context.SetReceiveTimeout(0)
+			out <- "timeout"
+		default:
+			context.SetReceiveTimeout(50 * time.Millisecond)
+		}
+	})
+
+	expectNothing(t, out, 100*time.Millisecond)
+	actor.Send(Message{"start"})
+	expect(t, out, "timeout")
+}
@@ -12,6 +12,20 @@ type Message []interface{}
 //  someActor.Send(actor.Message{actor.PoisonPill{}})
 type PoisonPill struct{}
 
+func isPoisonPill(msg Message) bool {
+	if len(msg) == 1 {
+		_, ok := msg[0].(PoisonPill)
+		return ok
+	}
+	return false
+}
+
+// envelope carries a message with its sender in the mailbox.
+type envelope struct {
+	message Message
+	sender  *Actor
+}
+
 // Receive is a type for Actor's message handler.
 // It is just an alias for func(msg Message, context *ActorContext).
 // For example, simple echo actor would be:
@@ -31,7 +45,32 @@ type Receive func(msg Message, context *ActorContext)
 //     Cause: "killed",
 //     Actor: <pointer to the actor>
 //   }}
+// If monitored actor was stopped by its supervisor, monitor will receive
+//   Message{Down{
+//     Cause: "stopped",
+//     Actor: <pointer to the actor>
+//   }}
+// If monitored actor was stopped because it exceeded the restart limit of its supervisor,
+// or it is a pool whose workers stopped too often, Cause will be "restart-limit".
+//
+// Down is sent after the actor's children stopped and its PostStop finished
+// (see WithStopTimeout), so that monitors can rely on the whole subtree being stopped.
 type Down struct {
 	Cause string
 	Actor *Actor
 }
+
+// DeadLetter is published to ActorSystem.DeadLetters when a message could not be delivered.
+//
+// It happens when a message was sent to a stopped actor, or a message was
+// left in the mailbox when the actor stopped.  Subscribers will receive
+//   Message{DeadLetter{
+//     Message:   <undelivered message>,
+//     Sender:    <pointer to the sender or nil>,
+//     Recipient: <pointer to the actor>,
+//   }}
+type DeadLetter struct {
+	Message   Message
+	Sender    *Actor
+	Recipient *Actor
+}
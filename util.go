@@ -1,11 +1,21 @@
 package actor
 
-import "github.com/dropbox/godropbox/container/set"
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/dropbox/godropbox/container/set"
+)
 
 // this maintains set and map simultaneously.
+// this is safe for concurrent use.
 type actorSet struct{
+	lock sync.RWMutex
 	s set.Set
 	m map[string]*Actor
+	// seq generates default names.
+	seq int
 }
 
 func newActorSet(s set.Set) *actorSet{
@@ -32,35 +42,98 @@ func actorSet2map(s set.Set) map[string]*Actor {
 }
 
 func (as *actorSet) Len() int{
+	as.lock.RLock()
+	defer as.lock.RUnlock()
 	return as.s.Len()
 }
 
 
 func (as *actorSet) Add(a *Actor){
+	as.lock.Lock()
+	defer as.lock.Unlock()
 	as.s.Add(a)
 	as.m[a.Name] = a
 }
 
+// TryAdd adds an actor unless another actor with the same name is in the set.
+func (as *actorSet) TryAdd(a *Actor) bool{
+	as.lock.Lock()
+	defer as.lock.Unlock()
+	if _, ok := as.m[a.Name]; ok {
+		return false
+	}
+	as.s.Add(a)
+	as.m[a.Name] = a
+	return true
+}
+
 func (as *actorSet) Remove(a *Actor) bool{
+	as.lock.Lock()
+	defer as.lock.Unlock()
 	r := as.s.Remove(a)
-	delete(as.m, a.Name)
+	// the name may be taken by another actor already.
+	if as.m[a.Name] == a {
+		delete(as.m, a.Name)
+	}
 	return r
 }
 
+// nextName returns a sequential name which is not used in the set.
+func (as *actorSet) nextName() string{
+	as.lock.Lock()
+	defer as.lock.Unlock()
+	for {
+		name := fmt.Sprint(as.seq)
+		as.seq++
+		if _, ok := as.m[name]; !ok {
+			return name
+		}
+	}
+}
+
+// Get returns an actor with a given name, or nil.
+func (as *actorSet) Get(name string) *Actor{
+	as.lock.RLock()
+	defer as.lock.RUnlock()
+	return as.m[name]
+}
+
+// Do calls f for a snapshot of actors so that f can modify the set.
 func (as *actorSet) Do(f func(actor *Actor)){
+	for _, a := range as.snapshot() {
+		f(a)
+	}
+}
+
+func (as *actorSet) snapshot() []*Actor {
+	as.lock.RLock()
+	defer as.lock.RUnlock()
+	actors := make([]*Actor, 0, as.s.Len())
 	as.s.Do(func(v interface{}){
 		if a, ok := v.(*Actor); ok{
-			f(a)
+			actors = append(actors, a)
 		}
 	})
+	return actors
 }
 
-func (as *actorSet) Subtract(s set.Set){
-	s.Do(func(v interface{}){
-		if a, ok := v.(*Actor); ok{
-			as.Remove(a)
-		}
-	})
+// sorted returns a snapshot of actors sorted by name.
+func (as *actorSet) sorted() []*Actor {
+	actors := as.snapshot()
+	sort.Sort(actorsByName(actors))
+	return actors
+}
+
+type actorsByName []*Actor
+
+func (actors actorsByName) Len() int {
+	return len(actors)
 }
 
+func (actors actorsByName) Less(i, j int) bool {
+	return actors[i].Name < actors[j].Name
+}
 
+func (actors actorsByName) Swap(i, j int) {
+	actors[i], actors[j] = actors[j], actors[i]
+}